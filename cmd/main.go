@@ -14,10 +14,14 @@ import (
 	"go.uber.org/zap"
 
 	"product-service/internal/handler"
+	"product-service/internal/jobs"
+	"product-service/internal/models"
 	"product-service/internal/repository"
 	"product-service/pkg/database"
 	"product-service/pkg/logger"
 	customMiddleware "product-service/pkg/middleware"
+	"product-service/pkg/storage"
+	"product-service/pkg/utils"
 )
 
 // CustomValidator implements validator.Validate
@@ -48,11 +52,17 @@ func main() {
 	// Middleware
 	e.Use(customMiddleware.RecoverMiddleware())
 
+	// Request correlation and caller identity for the audit trail
+	e.Use(echoMiddleware.RequestID())
+	e.Use(customMiddleware.RequestContextMiddleware())
+
 	// Add zap logger middleware
 	e.Use(logger.LoggerMiddleware(zapLogger))
 
 	// CORS and Security Middleware
-	e.Use(echoMiddleware.CORS())
+	e.Use(echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
+		ExposeHeaders: []string{"ETag", customMiddleware.IdempotentReplayedHeader},
+	}))
 	e.Use(echoMiddleware.SecureWithConfig(echoMiddleware.SecureConfig{
 		XSSProtection:         "1; mode=block",
 		ContentTypeNosniff:    "nosniff",
@@ -62,8 +72,9 @@ func main() {
 	}))
 
 	// Request Timeout
+	requestTimeout := 30 * time.Second
 	e.Use(echoMiddleware.TimeoutWithConfig(echoMiddleware.TimeoutConfig{
-		Timeout: 30 * time.Second,
+		Timeout: requestTimeout,
 	}))
 
 	// Create database connection
@@ -78,46 +89,269 @@ func main() {
 		)
 	}
 
+	// Image content of database-backed products is kept on the local filesystem
+	blobStore, err := storage.NewLocalStore(utils.GetEnv("BLOB_STORAGE_DIR", "./data/blobs"))
+	if err != nil {
+		zapLogger.Fatal("Failed to initialize blob storage",
+			zap.Error(err),
+		)
+	}
+
 	// Create repositories
 	// Database-backed repository
-	productRepo := repository.NewProductRepository(db)
+	productRepo := repository.NewProductRepository(db, blobStore)
 	productHandler := handler.NewProductHandler(productRepo)
 
-	// In-memory repository
-	memoryRepo := repository.NewProductMemoryRepository()
+	// In-memory repository; its images are lost on restart like its products
+	memoryRepo := repository.NewProductMemoryRepository(storage.NewMemoryStore())
 	memoryHandler := handler.NewProductMemoryHandler(memoryRepo)
 
+	// Background jobs are stopped when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Hard-delete soft-deleted products once their retention has elapsed
+	go jobs.StartPurger(jobsCtx,
+		utils.GetEnvDuration("PURGE_INTERVAL", time.Hour),
+		utils.GetEnvDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour),
+		map[string]jobs.Purger{
+			"postgres": productRepo,
+			"memory":   memoryRepo,
+		},
+	)
+
+	// Apply and revert scheduled price changes
+	go jobs.StartPriceScheduler(jobsCtx,
+		utils.GetEnvDuration("PRICE_SCHEDULER_INTERVAL", 15*time.Second),
+		map[string]jobs.ScheduleApplier{
+			"postgres": productRepo,
+			"memory":   memoryRepo,
+		},
+	)
+
 	// Create validator
 	validate := validator.New()
+	if err := validate.RegisterValidation("sku", models.ValidateSKU); err != nil {
+		zapLogger.Fatal("Failed to register SKU validation",
+			zap.Error(err),
+		)
+	}
+	if err := validate.RegisterValidation("tag", models.ValidateTag); err != nil {
+		zapLogger.Fatal("Failed to register tag validation",
+			zap.Error(err),
+		)
+	}
+	if err := validate.RegisterValidation("attrkey", models.ValidateAttributeKey); err != nil {
+		zapLogger.Fatal("Failed to register attribute key validation",
+			zap.Error(err),
+		)
+	}
+	if err := validate.RegisterValidation("attrvalue", models.ValidateAttributeValue); err != nil {
+		zapLogger.Fatal("Failed to register attribute value validation",
+			zap.Error(err),
+		)
+	}
 
 	// Set custom validator
 	e.Validator = &CustomValidator{validator: validate}
 
+	// Bulk deletes and generation are refused in production unless explicitly allowed
+	destructive := customMiddleware.DestructiveConfig{
+		Enabled: env != "production" || utils.GetEnvBool("ALLOW_DESTRUCTIVE_OPERATIONS", false),
+	}
+
+	// Bulk deletes honour dryRun=true, which writes nothing and needs no confirmation
+	bulkDelete := destructive
+	bulkDelete.DryRun = true
+
+	// Bulk updates are routine in production, so they only need administrator access and confirmation
+	bulkUpdate := customMiddleware.DestructiveConfig{Enabled: true, DryRun: true}
+
+	// Bearer tokens are verified with an HS256 secret and/or the public keys of a local JWKS file
+	jwtConfig := customMiddleware.JWTConfig{
+		Secret:   []byte(os.Getenv("JWT_SECRET")),
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+		Leeway:   utils.GetEnvDuration("JWT_LEEWAY", 30*time.Second),
+	}
+	if path := os.Getenv("JWT_JWKS_FILE"); path != "" {
+		keys, err := customMiddleware.LoadKeySet(path)
+		if err != nil {
+			zapLogger.Fatal("Failed to load JWKS file",
+				zap.Error(err),
+				zap.String("path", path),
+			)
+		}
+		jwtConfig.Keys = keys
+	}
+
+	// API keys of service-to-service clients live in PostgreSQL, or in a local file during development
+	var apiKeyStore repository.APIKeyStore = repository.NewAPIKeyRepository(db)
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		fileStore, err := repository.NewAPIKeyFileRepository(path)
+		if err != nil {
+			zapLogger.Fatal("Failed to load API keys file",
+				zap.Error(err),
+				zap.String("path", path),
+			)
+		}
+		apiKeyStore = fileStore
+	}
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyStore)
+
+	// Health, liveness and metrics routes stay public; the API accepts API keys and, once keys are
+	// configured, requires a bearer token from other callers
+	apiMiddleware := []echo.MiddlewareFunc{customMiddleware.APIKeyMiddleware(apiKeyHandler)}
+	if jwtConfig.Enabled() {
+		apiMiddleware = append(apiMiddleware, customMiddleware.JWTMiddleware(jwtConfig))
+	} else if env == "production" {
+		zapLogger.Fatal("JWT authentication is not configured; set JWT_SECRET or JWT_JWKS_FILE")
+	} else {
+		zapLogger.Warn("JWT authentication is not configured; the API is open")
+	}
+
+	// Replay responses of retried POST requests carrying an Idempotency-Key; keys are scoped to the
+	// identity verified by the middlewares above
+	apiMiddleware = append(apiMiddleware, customMiddleware.IdempotencyMiddleware(customMiddleware.IdempotencyConfig{
+		Store:           customMiddleware.NewMemoryIdempotencyStore(utils.GetEnvInt64("IDEMPOTENCY_STORE_MAX_BYTES", 64<<20)),
+		TTL:             utils.GetEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		InFlightTimeout: 10 * time.Second,
+		Lease:           requestTimeout + 10*time.Second,
+		MaxBodyBytes:    utils.GetEnvInt64("IDEMPOTENCY_MAX_BODY_BYTES", 1<<20),
+	}))
+
 	// Routes
-	v1 := e.Group("/api/v1")
+	v1 := e.Group("/api/v1", apiMiddleware...)
+
+	// API key management
+	v1.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+	v1.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+	v1.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
 
-	// DB-backed Product routes
+	// DB-backed Product and Category routes
 	v1.POST("/products", productHandler.CreateProduct)
+	v1.POST("/products/upsert", productHandler.UpsertProduct)
+	v1.POST("/products/batch", productHandler.BatchProducts)
 	v1.GET("/products", productHandler.ListProducts)
 	v1.GET("/products/all", productHandler.GetAllProducts)
+	v1.GET("/products/search", productHandler.SearchProducts)
+	v1.GET("/products/suggest", productHandler.SuggestProducts)
 	v1.GET("/products/:id", productHandler.GetProduct)
+	v1.GET("/products/by-sku/:sku", productHandler.GetProductBySKU)
 	v1.PUT("/products/:id", productHandler.UpdateProduct)
+	v1.PATCH("/products/:id", productHandler.PatchProduct)
 	v1.DELETE("/products/:id", productHandler.DeleteProduct)
-	v1.POST("/products/bulk/generate", productHandler.BulkGenerateProducts)
-	v1.DELETE("/products/bulk", productHandler.DeleteAllProducts)
+	v1.POST("/products/:id/restore", productHandler.RestoreProduct)
+	v1.POST("/products/:id/transition", productHandler.TransitionProduct)
+	v1.GET("/products/:id/history", productHandler.GetProductHistory)
+	v1.GET("/products/:id/history/:version", productHandler.GetProductHistoryVersion)
+	v1.GET("/products/:id/prices", productHandler.GetProductPrices)
+	v1.POST("/products/:id/schedules", productHandler.CreatePriceSchedule)
+	v1.GET("/products/:id/schedules", productHandler.ListPriceSchedules)
+	v1.DELETE("/products/:id/schedules/:scheduleId", productHandler.CancelPriceSchedule)
+	v1.POST("/products/:id/variants", productHandler.CreateVariant)
+	v1.GET("/products/:id/variants", productHandler.ListVariants)
+	v1.GET("/products/:id/variants/:variantId", productHandler.GetVariant)
+	v1.PUT("/products/:id/variants/:variantId", productHandler.UpdateVariant)
+	v1.DELETE("/products/:id/variants/:variantId", productHandler.DeleteVariant)
+	v1.GET("/products/:id/stock", productHandler.GetStock)
+	v1.GET("/products/:id/stock/movements", productHandler.GetStockMovements)
+	v1.POST("/products/:id/stock/adjust", productHandler.AdjustStock)
+	v1.POST("/products/:id/stock/reserve", productHandler.ReserveStock)
+	v1.POST("/products/:id/stock/release", productHandler.ReleaseStock)
+	v1.POST("/products/:id/images", productHandler.UploadImage)
+	v1.GET("/products/:id/images", productHandler.ListImages)
+	v1.PUT("/products/:id/images/order", productHandler.ReorderImages)
+	v1.GET("/products/:id/images/:imageId/content", productHandler.GetImageContent)
+	v1.GET("/products/:id/images/:imageId/thumbnail", productHandler.GetImageThumbnail)
+	v1.POST("/products/:id/images/:imageId/primary", productHandler.SetPrimaryImage)
+	v1.DELETE("/products/:id/images/:imageId", productHandler.DeleteImage)
+	v1.GET("/products/:id/translations", productHandler.ListTranslations)
+	v1.GET("/products/:id/translations/:locale", productHandler.GetTranslation)
+	v1.PUT("/products/:id/translations/:locale", productHandler.PutTranslation)
+	v1.DELETE("/products/:id/translations/:locale", productHandler.DeleteTranslation)
+	v1.POST("/products/bulk/generate", productHandler.BulkGenerateProducts,
+		customMiddleware.DestructiveGuard(destructive, "bulk_generate", productHandler.CountLiveProducts))
+	v1.POST("/products/bulk/update", productHandler.BulkUpdateProducts,
+		customMiddleware.DestructiveGuard(bulkUpdate, "bulk_update", productHandler.CountBulkTargets))
+	v1.POST("/products/bulk/delete", productHandler.BulkDeleteProducts,
+		customMiddleware.DestructiveGuard(bulkDelete, "bulk_delete", productHandler.CountBulkTargets))
+	v1.DELETE("/products/bulk", productHandler.DeleteAllProducts,
+		customMiddleware.DestructiveGuard(destructive, "delete_all", productHandler.CountLiveProducts))
 	v1.GET("/products/count", productHandler.GetProductCount)
-
-	// In-memory Product routes with "memory" prefix
+	v1.GET("/products/aggregate", productHandler.AggregateProducts)
+	v1.GET("/products/tags", productHandler.GetProductTags)
+
+	v1.POST("/categories", productHandler.CreateCategory)
+	v1.GET("/categories", productHandler.ListCategories)
+	v1.GET("/categories/tree", productHandler.GetCategoryTree)
+	v1.GET("/categories/:id", productHandler.GetCategory)
+	v1.PUT("/categories/:id", productHandler.UpdateCategory)
+	v1.DELETE("/categories/:id", productHandler.DeleteCategory)
+	v1.GET("/categories/:id/products", productHandler.ListCategoryProducts)
+
+	// In-memory Product and Category routes with "memory" prefix
 	memory := v1.Group("/memory")
 	memory.POST("/products", memoryHandler.CreateProduct)
+	memory.POST("/products/upsert", memoryHandler.UpsertProduct)
+	memory.POST("/products/batch", memoryHandler.BatchProducts)
 	memory.GET("/products", memoryHandler.ListProducts)
 	memory.GET("/products/all", memoryHandler.GetAllProducts)
+	memory.GET("/products/search", memoryHandler.SearchProducts)
+	memory.GET("/products/suggest", memoryHandler.SuggestProducts)
 	memory.GET("/products/:id", memoryHandler.GetProduct)
+	memory.GET("/products/by-sku/:sku", memoryHandler.GetProductBySKU)
 	memory.PUT("/products/:id", memoryHandler.UpdateProduct)
+	memory.PATCH("/products/:id", memoryHandler.PatchProduct)
 	memory.DELETE("/products/:id", memoryHandler.DeleteProduct)
-	memory.POST("/products/bulk/generate", memoryHandler.BulkGenerateProducts)
-	memory.DELETE("/products/bulk", memoryHandler.DeleteAllProducts)
+	memory.POST("/products/:id/restore", memoryHandler.RestoreProduct)
+	memory.POST("/products/:id/transition", memoryHandler.TransitionProduct)
+	memory.GET("/products/:id/history", memoryHandler.GetProductHistory)
+	memory.GET("/products/:id/history/:version", memoryHandler.GetProductHistoryVersion)
+	memory.GET("/products/:id/prices", memoryHandler.GetProductPrices)
+	memory.POST("/products/:id/schedules", memoryHandler.CreatePriceSchedule)
+	memory.GET("/products/:id/schedules", memoryHandler.ListPriceSchedules)
+	memory.DELETE("/products/:id/schedules/:scheduleId", memoryHandler.CancelPriceSchedule)
+	memory.POST("/products/:id/variants", memoryHandler.CreateVariant)
+	memory.GET("/products/:id/variants", memoryHandler.ListVariants)
+	memory.GET("/products/:id/variants/:variantId", memoryHandler.GetVariant)
+	memory.PUT("/products/:id/variants/:variantId", memoryHandler.UpdateVariant)
+	memory.DELETE("/products/:id/variants/:variantId", memoryHandler.DeleteVariant)
+	memory.GET("/products/:id/stock", memoryHandler.GetStock)
+	memory.GET("/products/:id/stock/movements", memoryHandler.GetStockMovements)
+	memory.POST("/products/:id/stock/adjust", memoryHandler.AdjustStock)
+	memory.POST("/products/:id/stock/reserve", memoryHandler.ReserveStock)
+	memory.POST("/products/:id/stock/release", memoryHandler.ReleaseStock)
+	memory.POST("/products/:id/images", memoryHandler.UploadImage)
+	memory.GET("/products/:id/images", memoryHandler.ListImages)
+	memory.PUT("/products/:id/images/order", memoryHandler.ReorderImages)
+	memory.GET("/products/:id/images/:imageId/content", memoryHandler.GetImageContent)
+	memory.GET("/products/:id/images/:imageId/thumbnail", memoryHandler.GetImageThumbnail)
+	memory.POST("/products/:id/images/:imageId/primary", memoryHandler.SetPrimaryImage)
+	memory.DELETE("/products/:id/images/:imageId", memoryHandler.DeleteImage)
+	memory.GET("/products/:id/translations", memoryHandler.ListTranslations)
+	memory.GET("/products/:id/translations/:locale", memoryHandler.GetTranslation)
+	memory.PUT("/products/:id/translations/:locale", memoryHandler.PutTranslation)
+	memory.DELETE("/products/:id/translations/:locale", memoryHandler.DeleteTranslation)
+	memory.POST("/products/bulk/generate", memoryHandler.BulkGenerateProducts,
+		customMiddleware.DestructiveGuard(destructive, "bulk_generate", memoryHandler.CountLiveProducts))
+	memory.POST("/products/bulk/update", memoryHandler.BulkUpdateProducts,
+		customMiddleware.DestructiveGuard(bulkUpdate, "bulk_update", memoryHandler.CountBulkTargets))
+	memory.POST("/products/bulk/delete", memoryHandler.BulkDeleteProducts,
+		customMiddleware.DestructiveGuard(bulkDelete, "bulk_delete", memoryHandler.CountBulkTargets))
+	memory.DELETE("/products/bulk", memoryHandler.DeleteAllProducts,
+		customMiddleware.DestructiveGuard(destructive, "delete_all", memoryHandler.CountLiveProducts))
 	memory.GET("/products/count", memoryHandler.GetProductCount)
+	memory.GET("/products/aggregate", memoryHandler.AggregateProducts)
+	memory.GET("/products/tags", memoryHandler.GetProductTags)
+
+	memory.POST("/categories", memoryHandler.CreateCategory)
+	memory.GET("/categories", memoryHandler.ListCategories)
+	memory.GET("/categories/tree", memoryHandler.GetCategoryTree)
+	memory.GET("/categories/:id", memoryHandler.GetCategory)
+	memory.PUT("/categories/:id", memoryHandler.UpdateCategory)
+	memory.DELETE("/categories/:id", memoryHandler.DeleteCategory)
+	memory.GET("/categories/:id/products", memoryHandler.ListCategoryProducts)
 
 	// Prometheus metrics route (placeholder for future implementation)
 	e.GET("/metrics", func(c echo.Context) error {
@@ -195,6 +429,7 @@ func main() {
 		)
 	case <-shutdown:
 		zapLogger.Info("Starting graceful shutdown")
+		stopJobs()
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := e.Shutdown(ctx); err != nil {
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// parseAggregateOptions reads the statistics an aggregate request asks for, falling back
+// to the defaults for parameters left out
+func parseAggregateOptions(params url.Values) (models.AggregateOptions, error) {
+	options := models.AggregateOptions{
+		Percentiles:      models.DefaultPercentiles,
+		PriceBuckets:     models.DefaultPriceBuckets,
+		CreatedInterval:  models.IntervalDay,
+		NamePrefixLength: models.DefaultNamePrefixLength,
+	}
+
+	if value := params.Get("percentiles"); value != "" {
+		options.Percentiles = nil
+		for _, item := range strings.Split(value, ",") {
+			percentile, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+			if err != nil || percentile < 0 || percentile > 100 {
+				return options, fmt.Errorf("invalid percentile %q; use numbers from 0 to 100", item)
+			}
+			options.Percentiles = append(options.Percentiles, percentile)
+		}
+	}
+
+	if value := params.Get("priceBuckets"); value != "" {
+		buckets, err := strconv.Atoi(value)
+		if err != nil || buckets < 1 || buckets > models.MaxPriceBuckets {
+			return options, fmt.Errorf("priceBuckets must be between 1 and %d", models.MaxPriceBuckets)
+		}
+		options.PriceBuckets = buckets
+	}
+
+	if value := params.Get("createdInterval"); value != "" {
+		switch value {
+		case models.IntervalDay, models.IntervalWeek, models.IntervalMonth:
+			options.CreatedInterval = value
+		default:
+			return options, errors.New("createdInterval must be day, week or month")
+		}
+	}
+
+	if value := params.Get("groupBy"); value != "" {
+		for _, dimension := range strings.Split(value, ",") {
+			dimension = strings.TrimSpace(dimension)
+			switch dimension {
+			case models.GroupByStatus, models.GroupByCategory, models.GroupByNamePrefix:
+				if !models.ContainsString(options.GroupBy, dimension) {
+					options.GroupBy = append(options.GroupBy, dimension)
+				}
+			default:
+				return options, fmt.Errorf("unknown groupBy %q; use status, category or namePrefix", dimension)
+			}
+		}
+	}
+
+	if value := params.Get("namePrefixLength"); value != "" {
+		length, err := strconv.Atoi(value)
+		if err != nil || length < 1 || length > models.MaxNamePrefixLength {
+			return options, fmt.Errorf("namePrefixLength must be between 1 and %d", models.MaxNamePrefixLength)
+		}
+		options.NamePrefixLength = length
+	}
+
+	return options, nil
+}
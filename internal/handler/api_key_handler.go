@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/pkg/logger"
+	customMiddleware "product-service/pkg/middleware"
+	"product-service/pkg/requestctx"
+)
+
+// APIKeyHandler handles HTTP requests for API keys and resolves the keys clients present
+type APIKeyHandler struct {
+	store  repository.APIKeyStore
+	logger *zap.Logger
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler
+func NewAPIKeyHandler(store repository.APIKeyStore) *APIKeyHandler {
+	return &APIKeyHandler{
+		store:  store,
+		logger: logger.GetLogger(),
+	}
+}
+
+// CreateAPIKey handles POST request to issue a new API key; the key itself is only returned here
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	if !customMiddleware.IsAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Managing API keys requires administrator access"})
+	}
+
+	var req models.APIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind API key request",
+			zap.Error(err),
+			zap.String("handler", "CreateAPIKey"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("API key validation failed",
+			zap.Error(err),
+			zap.String("handler", "CreateAPIKey"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	ctx := c.Request().Context()
+	apiKey, key, err := req.NewAPIKey(requestctx.Actor(ctx))
+	if err == nil {
+		err = h.store.CreateAPIKey(ctx, apiKey)
+	}
+	if err != nil {
+		h.logger.Error("Failed to create API key",
+			zap.Error(err),
+			zap.String("handler", "CreateAPIKey"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create API key"})
+	}
+
+	h.logger.Info("API key created",
+		zap.String("api_key_id", apiKey.ID.String()),
+		zap.String("name", apiKey.Name),
+		zap.Strings("scopes", apiKey.Scopes),
+		zap.String("actor", apiKey.CreatedBy),
+	)
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"apiKey": apiKey,
+		"key":    key,
+	})
+}
+
+// ListAPIKeys handles GET request to list all API keys without their secrets
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	if !customMiddleware.IsAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Managing API keys requires administrator access"})
+	}
+
+	keys, err := h.store.ListAPIKeys(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to list API keys",
+			zap.Error(err),
+			zap.String("handler", "ListAPIKeys"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list API keys"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"apiKeys": keys,
+	})
+}
+
+// RevokeAPIKey handles DELETE request to revoke an API key; revoked keys are kept for the record
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	if !customMiddleware.IsAdmin(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Managing API keys requires administrator access"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid API key ID"})
+	}
+
+	ctx := c.Request().Context()
+	apiKey, err := h.store.RevokeAPIKey(ctx, id)
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+	}
+	if err != nil {
+		h.logger.Error("Failed to revoke API key",
+			zap.Error(err),
+			zap.String("handler", "RevokeAPIKey"),
+			zap.String("api_key_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke API key"})
+	}
+
+	h.logger.Info("API key revoked",
+		zap.String("api_key_id", id.String()),
+		zap.String("actor", requestctx.Actor(ctx)),
+	)
+
+	return c.JSON(http.StatusOK, apiKey)
+}
+
+// AuthenticateAPIKey resolves a presented key to its client for the API key middleware
+func (h *APIKeyHandler) AuthenticateAPIKey(ctx context.Context, key string) (*customMiddleware.APIKeyIdentity, error) {
+	apiKey, err := h.store.FindAPIKeyByHash(ctx, models.HashAPIKey(key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &customMiddleware.APIKeyIdentity{
+		ID:        apiKey.ID.String(),
+		Name:      apiKey.Name,
+		Scopes:    apiKey.Scopes,
+		RateLimit: apiKey.RateLimit,
+	}, nil
+}
+
+// TouchAPIKey records the last use of a key for the API key middleware
+func (h *APIKeyHandler) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		return err
+	}
+	return h.store.TouchAPIKey(ctx, keyID, usedAt)
+}
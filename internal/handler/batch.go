@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/pkg/jsonpatch"
+)
+
+// parseAtomic reads the atomic query parameter of a batch request, true unless set otherwise
+func parseAtomic(c echo.Context) (bool, error) {
+	value := c.QueryParam("atomic")
+	if value == "" {
+		return true, nil
+	}
+	atomic, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("atomic must be a boolean")
+	}
+	return atomic, nil
+}
+
+// describeBatchResults fills in the status and error message of each batch result, counts the
+// failures and picks the status of the whole response
+func describeBatchResults(ops []models.BatchOperation, results []models.BatchResult, committed bool) (int, int) {
+	failed := 0
+	status := http.StatusOK
+	for i := range results {
+		result := &results[i]
+		result.Status, result.Error = batchResultStatus(&ops[result.Index], result)
+		if result.Err == nil {
+			continue
+		}
+		failed++
+
+		// A rolled back batch reports the client error behind it, or a server error if one caused it
+		if !committed && result.Status != http.StatusFailedDependency {
+			if result.Status >= http.StatusInternalServerError {
+				status = http.StatusInternalServerError
+			} else if status != http.StatusInternalServerError {
+				status = http.StatusUnprocessableEntity
+			}
+		}
+	}
+	return status, failed
+}
+
+// batchResultStatus maps the outcome of a batch operation to an HTTP status and error message
+func batchResultStatus(op *models.BatchOperation, result *models.BatchResult) (int, string) {
+	err := result.Err
+	switch {
+	case err == nil && result.Op == models.BatchOpCreate:
+		return http.StatusCreated, ""
+	case err == nil:
+		return http.StatusOK, ""
+	case errors.Is(err, repository.ErrBatchAborted):
+		return http.StatusFailedDependency, err.Error()
+	case errors.Is(err, repository.ErrInvalidOperation):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, repository.ErrProductNotFound):
+		return http.StatusNotFound, "Product not found"
+	case errors.Is(err, repository.ErrVersionMismatch) && op.Version != 0:
+		return http.StatusPreconditionFailed, "Product has been modified"
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return http.StatusConflict, "Product was modified concurrently, please retry"
+	case errors.Is(err, repository.ErrProductExists):
+		return http.StatusConflict, "Product already exists"
+	case errors.Is(err, repository.ErrDuplicateSKU), errors.Is(err, jsonpatch.ErrTestFailed):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, repository.ErrInvalidProduct), errors.Is(err, repository.ErrInvalidPatch),
+		errors.Is(err, repository.ErrUnknownCategory), errors.Is(err, repository.ErrInvalidAttributes):
+		return http.StatusUnprocessableEntity, err.Error()
+	}
+	return http.StatusInternalServerError, "Failed to apply operation"
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+)
+
+// parseBulkFilter builds the filter of a bulk operation from the list filter parameters and reads
+// its dryRun flag. Bulk operations only apply to live products at their current prices.
+// On failure it returns the HTTP status the client should receive.
+func parseBulkFilter(c echo.Context) (models.ProductFilter, bool, int, error) {
+	params := c.QueryParams()
+	if params.Get("includeDeleted") != "" {
+		return models.ProductFilter{}, false, http.StatusBadRequest, errors.New("includeDeleted is not supported for bulk operations")
+	}
+	if params.Get("asOf") != "" {
+		return models.ProductFilter{}, false, http.StatusBadRequest, errors.New("asOf is not supported for bulk operations")
+	}
+
+	filter, status, err := parseProductFilter(c, params)
+	if err != nil {
+		return filter, false, status, err
+	}
+
+	dryRun := false
+	if value := params.Get("dryRun"); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return filter, false, http.StatusBadRequest, errors.New("dryRun must be a boolean")
+		}
+	}
+	return filter, dryRun, 0, nil
+}
@@ -0,0 +1,321 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// CreateCategory handles POST request to create a new category in memory
+func (h *ProductMemoryHandler) CreateCategory(c echo.Context) error {
+	var req models.CategoryRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind category request",
+			zap.Error(err),
+			zap.String("handler", "CreateCategory (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Category validation failed",
+			zap.Error(err),
+			zap.String("handler", "CreateCategory (Memory)"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Save to memory
+	category := req.ToCategory()
+	if err := h.repo.CreateCategory(c.Request().Context(), &category); err != nil {
+		h.logger.Error("Failed to create category in memory",
+			zap.Error(err),
+			zap.String("handler", "CreateCategory (Memory)"),
+			zap.Any("category", category),
+		)
+		if errors.Is(err, repository.ErrUnknownCategory) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Parent category does not exist"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create category"})
+	}
+
+	h.logger.Info("Category created successfully in memory",
+		zap.String("category_id", category.ID.String()),
+		zap.String("category_name", category.Name),
+	)
+
+	return c.JSON(http.StatusCreated, category)
+}
+
+// ListCategories handles GET request to list all categories from memory
+func (h *ProductMemoryHandler) ListCategories(c echo.Context) error {
+	categories, err := h.repo.ListCategories(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to retrieve categories from memory",
+			zap.Error(err),
+			zap.String("handler", "ListCategories (Memory)"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve categories"})
+	}
+
+	h.logger.Info("Categories listed successfully from memory",
+		zap.Int("returned_count", len(categories)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"categories": categories,
+		"totalCount": len(categories),
+	})
+}
+
+// GetCategoryTree handles GET request to retrieve the category hierarchy from memory
+func (h *ProductMemoryHandler) GetCategoryTree(c echo.Context) error {
+	categories, err := h.repo.ListCategories(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to retrieve categories from memory",
+			zap.Error(err),
+			zap.String("handler", "GetCategoryTree (Memory)"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve categories"})
+	}
+
+	h.logger.Info("Category tree retrieved successfully from memory",
+		zap.Int("category_count", len(categories)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"categories": models.BuildCategoryTree(categories),
+	})
+}
+
+// GetCategory handles GET request to retrieve a specific category from memory
+func (h *ProductMemoryHandler) GetCategory(c echo.Context) error {
+	// Parse category ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid category ID",
+			zap.Error(err),
+			zap.String("handler", "GetCategory (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+	}
+
+	// Retrieve category
+	category, err := h.repo.GetCategory(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve category from memory",
+			zap.Error(err),
+			zap.String("handler", "GetCategory (Memory)"),
+			zap.String("category_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve category"})
+	}
+
+	h.logger.Info("Category retrieved successfully from memory",
+		zap.String("category_id", category.ID.String()),
+		zap.String("category_name", category.Name),
+	)
+
+	return c.JSON(http.StatusOK, category)
+}
+
+// UpdateCategory handles PUT request to update or move a category in memory
+func (h *ProductMemoryHandler) UpdateCategory(c echo.Context) error {
+	// Parse category ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid category ID",
+			zap.Error(err),
+			zap.String("handler", "UpdateCategory (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+	}
+
+	// Parse request body
+	var req models.CategoryRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind category update request",
+			zap.Error(err),
+			zap.String("handler", "UpdateCategory (Memory)"),
+			zap.String("category_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Category update validation failed",
+			zap.Error(err),
+			zap.String("handler", "UpdateCategory (Memory)"),
+			zap.String("category_id", id.String()),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Update category in memory
+	category, err := h.repo.UpdateCategory(c.Request().Context(), id, &req)
+	if err != nil {
+		h.logger.Error("Failed to update category in memory",
+			zap.Error(err),
+			zap.String("handler", "UpdateCategory (Memory)"),
+			zap.String("category_id", id.String()),
+			zap.Any("request", req),
+		)
+		switch {
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		case errors.Is(err, repository.ErrUnknownCategory):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Parent category does not exist"})
+		case errors.Is(err, repository.ErrCategoryCycle):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Category cannot be moved below itself or its descendants"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update category"})
+	}
+
+	h.logger.Info("Category updated successfully in memory",
+		zap.String("category_id", category.ID.String()),
+		zap.String("category_name", category.Name),
+	)
+
+	return c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory handles DELETE request to remove an unused category from memory
+func (h *ProductMemoryHandler) DeleteCategory(c echo.Context) error {
+	// Parse category ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid category ID",
+			zap.Error(err),
+			zap.String("handler", "DeleteCategory (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+	}
+
+	// Delete category
+	if err := h.repo.DeleteCategory(c.Request().Context(), id); err != nil {
+		h.logger.Error("Failed to delete category from memory",
+			zap.Error(err),
+			zap.String("handler", "DeleteCategory (Memory)"),
+			zap.String("category_id", id.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrCategoryNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		case errors.Is(err, repository.ErrCategoryInUse):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Category still has subcategories or products"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete category"})
+	}
+
+	h.logger.Info("Category deleted successfully from memory",
+		zap.String("category_id", id.String()),
+	)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Category deleted successfully"})
+}
+
+// ListCategoryProducts handles GET request to list the products of a category from memory
+func (h *ProductMemoryHandler) ListCategoryProducts(c echo.Context) error {
+	// Parse category ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid category ID",
+			zap.Error(err),
+			zap.String("handler", "ListCategoryProducts (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category ID"})
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	// Parse filter parameters; the path decides the category
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "ListCategoryProducts (Memory)"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+	filter.CategoryID = &id
+
+	if _, err := h.repo.GetCategory(c.Request().Context(), id); err != nil {
+		h.logger.Warn("Failed to retrieve category from memory",
+			zap.Error(err),
+			zap.String("handler", "ListCategoryProducts (Memory)"),
+			zap.String("category_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrCategoryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve products"})
+	}
+
+	// Retrieve products
+	products, err := h.repo.List(c.Request().Context(), page, pageSize, filter)
+	if err != nil {
+		h.logger.Error("Failed to retrieve category products from memory",
+			zap.Error(err),
+			zap.String("handler", "ListCategoryProducts (Memory)"),
+			zap.String("category_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve products"})
+	}
+
+	// Get total count for pagination metadata
+	totalCount, err := h.repo.Count(c.Request().Context(), filter)
+	if err != nil {
+		h.logger.Warn("Failed to retrieve total category product count",
+			zap.Error(err),
+			zap.String("handler", "ListCategoryProducts (Memory)"),
+		)
+		totalCount = 0
+	}
+
+	h.logger.Info("Category products listed successfully from memory",
+		zap.String("category_id", id.String()),
+		zap.Bool("include_descendants", filter.IncludeDescendants),
+		zap.Int("total_count", totalCount),
+		zap.Int("returned_count", len(products)),
+	)
+
+	setContentLanguage(c, products...)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"categoryId": id,
+		"products":   products,
+		"page":       page,
+		"pageSize":   pageSize,
+		"totalCount": totalCount,
+	})
+}
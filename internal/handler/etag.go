@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+)
+
+// Conditional request headers (RFC 9110)
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// productETag returns the strong entity tag for the current product version
+func productETag(product *models.Product) string {
+	return `"` + strconv.FormatInt(product.Version, 10) + `"`
+}
+
+// setProductETag writes the product's entity tag to the response headers
+func setProductETag(c echo.Context, product *models.Product) {
+	c.Response().Header().Set(headerETag, productETag(product))
+}
+
+// etagMatches reports whether an If-Match / If-None-Match header value matches the given tag.
+// Weak comparison ignores the W/ prefix; strong comparison never matches weak tags.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+	customMiddleware "product-service/pkg/middleware"
+)
+
+// attributeFilterPrefix marks query parameters that filter on product attributes
+const attributeFilterPrefix = "attr."
+
+// parseProductFilter builds a product filter from list query parameters.
+// On failure it returns the HTTP status the client should receive.
+func parseProductFilter(c echo.Context, params url.Values) (models.ProductFilter, int, error) {
+	var filter models.ProductFilter
+
+	if value := params.Get("includeDeleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, http.StatusBadRequest, errors.New("includeDeleted must be a boolean")
+		}
+		if includeDeleted && !customMiddleware.IsAdmin(c) {
+			return filter, http.StatusForbidden, errors.New("includeDeleted requires administrator access")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	// Public callers see active products unless they ask for discontinued ones too
+	admin := customMiddleware.IsAdmin(c)
+	if value := params.Get("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if !models.IsValidStatus(status) {
+				return filter, http.StatusBadRequest, fmt.Errorf("invalid status %q", status)
+			}
+			if !admin && !models.ContainsString(models.PublicStatuses, status) {
+				return filter, http.StatusForbidden, fmt.Errorf("listing %s products requires administrator access", status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	} else if !admin {
+		filter.Statuses = []string{models.StatusActive}
+	}
+
+	if value := params.Get("asOf"); value != "" {
+		asOf, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return filter, http.StatusBadRequest, errors.New("asOf must be an RFC 3339 timestamp")
+		}
+		filter.AsOf = &asOf
+	}
+
+	if value := params.Get("name"); value != "" {
+		if utf8.RuneCountInString(value) > models.MaxNameFilterLength {
+			return filter, http.StatusBadRequest, fmt.Errorf("name must be at most %d characters", models.MaxNameFilterLength)
+		}
+		filter.Name = value
+	}
+
+	minPrice, err := parsePriceBound(params, "minPrice")
+	if err != nil {
+		return filter, http.StatusBadRequest, err
+	}
+	maxPrice, err := parsePriceBound(params, "maxPrice")
+	if err != nil {
+		return filter, http.StatusBadRequest, err
+	}
+	filter.MinPrice, filter.MaxPrice = minPrice, maxPrice
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return filter, http.StatusBadRequest, errors.New("minPrice must not exceed maxPrice")
+	}
+
+	if value := params.Get("categoryId"); value != "" {
+		categoryID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, http.StatusBadRequest, errors.New("categoryId must be a UUID")
+		}
+		filter.CategoryID = &categoryID
+	}
+
+	if value := params.Get("includeDescendants"); value != "" {
+		includeDescendants, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, http.StatusBadRequest, errors.New("includeDescendants must be a boolean")
+		}
+		filter.IncludeDescendants = includeDescendants
+	}
+
+	if value := params.Get("tags"); value != "" {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if !models.IsValidTag(tag) {
+				return filter, http.StatusBadRequest, fmt.Errorf("invalid tag %q", tag)
+			}
+			filter.Tags = append(filter.Tags, tag)
+		}
+
+		filter.TagMode = models.TagModeAll
+		if mode := params.Get("tagMode"); mode != "" {
+			if mode != models.TagModeAll && mode != models.TagModeAny {
+				return filter, http.StatusBadRequest, errors.New("tagMode must be all or any")
+			}
+			filter.TagMode = mode
+		}
+	}
+
+	if value := params.Get("include"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			switch strings.TrimSpace(name) {
+			case "variants":
+				filter.Include.Variants = true
+			case "priceRange":
+				filter.Include.PriceRange = true
+			case "images":
+				filter.Include.Images = true
+			default:
+				return filter, http.StatusBadRequest, fmt.Errorf("unknown include %q; use variants, priceRange or images", name)
+			}
+		}
+	}
+
+	filter.Locales = models.LocaleChain(c.Request().Header.Get(headerAcceptLanguage))
+
+	attributes, err := parseAttributeFilters(params)
+	if err != nil {
+		return filter, http.StatusBadRequest, err
+	}
+	filter.Attributes = attributes
+
+	return filter, 0, nil
+}
+
+// isStatusVisible reports whether a product read by ID or SKU may be shown to the caller.
+// Without a status parameter, administrators see every state and other callers the public
+// states, so discontinued products stay reachable; with one, the product must be in a listed state.
+func isStatusVisible(c echo.Context, filter models.ProductFilter, product *models.Product) bool {
+	allowed := filter.Statuses
+	if c.QueryParam("status") == "" {
+		if customMiddleware.IsAdmin(c) {
+			return true
+		}
+		allowed = models.PublicStatuses
+	}
+	return models.ContainsString(allowed, product.Status)
+}
+
+// parsePriceBound reads an optional non-negative price query parameter
+func parsePriceBound(params url.Values, name string) (*float64, error) {
+	value := params.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 || math.IsInf(price, 0) {
+		return nil, fmt.Errorf("%s must be a non-negative number", name)
+	}
+	return &price, nil
+}
+
+// parseAttributeFilters reads attr.<key>=value and attr.<key>.<op>=value query parameters.
+// Range operators need a numeric value; conditions are returned in a stable order.
+func parseAttributeFilters(params url.Values) ([]models.AttributeFilter, error) {
+	names := make([]string, 0)
+	for name := range params {
+		if strings.HasPrefix(name, attributeFilterPrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var filters []models.AttributeFilter
+	for _, name := range names {
+		key, op, _ := strings.Cut(strings.TrimPrefix(name, attributeFilterPrefix), ".")
+		if op == "" {
+			op = models.AttributeOpEq
+		}
+		if !models.IsValidAttributeKey(key) {
+			return nil, fmt.Errorf("invalid attribute key %q", key)
+		}
+
+		for _, value := range params[name] {
+			filter := models.AttributeFilter{Key: key, Op: op, Value: value}
+			switch op {
+			case models.AttributeOpEq, models.AttributeOpNe:
+			case models.AttributeOpGt, models.AttributeOpGte, models.AttributeOpLt, models.AttributeOpLte:
+				number, ok := filter.NumberValue()
+				if !ok {
+					return nil, fmt.Errorf("%s must be a number", name)
+				}
+				filter.Number = number
+			default:
+				return nil, fmt.Errorf("unknown attribute operator %q; use eq, ne, gt, gte, lt or lte", op)
+			}
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+	"product-service/pkg/imaging"
+	"product-service/pkg/utils"
+)
+
+const (
+	// defaultMaxImageBytes limits uploads unless IMAGE_MAX_BYTES says otherwise
+	defaultMaxImageBytes = 10 << 20
+
+	// multipartOverhead allows for the form boundaries and headers around the file
+	multipartOverhead = 64 << 10
+
+	// imageFormField is the multipart form field carrying the uploaded file
+	imageFormField = "file"
+
+	// thumbnailSize is the bounding box of generated thumbnails in pixels
+	thumbnailSize = 256
+
+	// maxFilenameLength matches the filename column
+	maxFilenameLength = 255
+)
+
+// imageUpload is an uploaded image with its generated thumbnail
+type imageUpload struct {
+	image     models.ProductImage
+	content   []byte
+	thumbnail []byte
+}
+
+// readImageUpload reads the multipart image upload of a product, sniffs its content type and
+// generates its thumbnail. On failure it returns the HTTP status to respond with.
+func readImageUpload(c echo.Context, productID uuid.UUID) (*imageUpload, int, error) {
+	maxBytes := utils.GetEnvInt64("IMAGE_MAX_BYTES", defaultMaxImageBytes)
+	tooLarge := fmt.Errorf("image exceeds the %d byte limit", maxBytes)
+
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBytes+multipartOverhead)
+
+	file, err := c.FormFile(imageFormField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, http.StatusRequestEntityTooLarge, tooLarge
+		}
+		return nil, http.StatusBadRequest, fmt.Errorf("expected a multipart image in form field %q", imageFormField)
+	}
+	if file.Size > maxBytes {
+		return nil, http.StatusRequestEntityTooLarge, tooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("uploaded image could not be read")
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, maxBytes+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("uploaded image could not be read")
+	}
+	if int64(len(content)) > maxBytes {
+		return nil, http.StatusRequestEntityTooLarge, tooLarge
+	}
+
+	// Trust the bytes, not the declared Content-Type
+	contentType, err := imaging.DetectContentType(content)
+	if err != nil {
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported image type %s; use JPEG, PNG or GIF", contentType)
+	}
+
+	img, err := imaging.Decode(content, contentType)
+	if errors.Is(err, imaging.ErrTooManyPixels) {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("image exceeds %d pixels", imaging.MaxPixels)
+	}
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, errors.New("uploaded image could not be decoded")
+	}
+
+	thumbnail, thumbnailType, err := imaging.Encode(imaging.Thumbnail(img, thumbnailSize), contentType)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to generate thumbnail")
+	}
+
+	filename := filepath.Base(file.Filename)
+	if len(filename) > maxFilenameLength {
+		filename = filename[:maxFilenameLength]
+	}
+
+	bounds := img.Bounds()
+	return &imageUpload{
+		image: models.NewProductImage(productID, filename, contentType, int64(len(content)),
+			bounds.Dx(), bounds.Dy(), thumbnailType),
+		content:   content,
+		thumbnail: thumbnail,
+	}, 0, nil
+}
+
+// parseImageIDs reads the product and image IDs of an image route
+func parseImageIDs(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid product ID")
+	}
+	imageID, err := uuid.Parse(c.Param("imageId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid image ID")
+	}
+	return id, imageID, nil
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+)
+
+// Content negotiation headers (RFC 9110)
+const (
+	headerAcceptLanguage  = "Accept-Language"
+	headerContentLanguage = "Content-Language"
+)
+
+// setContentLanguage announces that the response depends on Accept-Language and, when the
+// caller negotiated a locale, which locales the products are served in
+func setContentLanguage(c echo.Context, products ...models.Product) {
+	header := c.Response().Header()
+	header.Add(echo.HeaderVary, headerAcceptLanguage)
+
+	seen := make(map[string]bool)
+	locales := make([]string, 0, 1)
+	for _, product := range products {
+		if product.Locale != "" && !seen[product.Locale] {
+			seen[product.Locale] = true
+			locales = append(locales, product.Locale)
+		}
+	}
+	if len(locales) > 0 {
+		sort.Strings(locales)
+		header.Set(headerContentLanguage, strings.Join(locales, ", "))
+	}
+}
+
+// isTranslated reports whether the product is served in another locale than its own
+func isTranslated(product *models.Product) bool {
+	return product.Locale != "" && product.Locale != models.DefaultLocale
+}
+
+// parseTranslationRoute reads the product ID and the canonical locale of a translation route.
+// The default locale has no translation: it is served by the product itself.
+func parseTranslationRoute(c echo.Context) (uuid.UUID, string, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, "", errors.New("Invalid product ID")
+	}
+	locale, err := models.CanonicalLocale(c.Param("locale"))
+	if err != nil {
+		return uuid.Nil, "", errors.New("Invalid locale")
+	}
+	if locale == models.DefaultLocale {
+		return uuid.Nil, "", errors.New("The default locale is served by the product itself; update the product instead")
+	}
+	return id, locale, nil
+}
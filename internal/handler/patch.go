@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"product-service/internal/models"
+	"product-service/pkg/jsonpatch"
+)
+
+// applyProductPatch applies the request's patch document to the stored product.
+// On failure it returns the HTTP status the client should receive.
+func applyProductPatch(c echo.Context, current *models.Product) (*models.ProductRequest, int, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != jsonpatch.MergePatchMediaType && mediaType != jsonpatch.JSONPatchMediaType) {
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported patch media type, use %s or %s",
+			jsonpatch.MergePatchMediaType, jsonpatch.JSONPatchMediaType)
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid request payload")
+	}
+
+	document, err := json.Marshal(current.ToRequest())
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	var patched []byte
+	if mediaType == jsonpatch.MergePatchMediaType {
+		patched, err = jsonpatch.MergePatch(document, body)
+	} else {
+		patched, err = jsonpatch.Apply(document, body)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return nil, http.StatusConflict, err
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	// Reject patches that introduce fields the product does not have
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	var req models.ProductRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	return &req, 0, nil
+}
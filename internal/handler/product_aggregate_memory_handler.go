@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// AggregateProducts handles GET request to compute counts and price statistics of products from memory
+func (h *ProductMemoryHandler) AggregateProducts(c echo.Context) error {
+	// Parse filter parameters
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "AggregateProducts (Memory)"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Aggregates cover current prices only
+	if filter.AsOf != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "asOf is not supported for aggregates"})
+	}
+
+	options, err := parseAggregateOptions(c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid aggregate options",
+			zap.Error(err),
+			zap.String("handler", "AggregateProducts (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Aggregate products in memory
+	aggregate, err := h.repo.Aggregate(c.Request().Context(), filter, options)
+	if err != nil {
+		h.logger.Error("Failed to aggregate products in memory",
+			zap.Error(err),
+			zap.String("handler", "AggregateProducts (Memory)"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to aggregate products"})
+	}
+
+	h.logger.Info("Products aggregated successfully in memory",
+		zap.Int("total_count", aggregate.Count),
+		zap.Strings("group_by", options.GroupBy),
+	)
+
+	return c.JSON(http.StatusOK, aggregate)
+}
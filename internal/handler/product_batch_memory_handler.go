@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+)
+
+// BatchProducts handles POST request to run a batch of product creates, updates, patches and deletes in memory
+func (h *ProductMemoryHandler) BatchProducts(c echo.Context) error {
+	atomic, err := parseAtomic(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var req models.BatchRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind batch request",
+			zap.Error(err),
+			zap.String("handler", "BatchProducts (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Bound the batch before validating its operations
+	if len(req.Operations) > models.MaxBatchOperations {
+		h.logger.Warn("Batch too large",
+			zap.String("handler", "BatchProducts (Memory)"),
+			zap.Int("operation_count", len(req.Operations)),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{
+			"error": fmt.Sprintf("A batch holds at most %d operations", models.MaxBatchOperations),
+		})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Batch validation failed",
+			zap.Error(err),
+			zap.String("handler", "BatchProducts (Memory)"),
+			zap.Int("operation_count", len(req.Operations)),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Run batch in memory
+	results, committed, err := h.repo.Batch(c.Request().Context(), req.Operations, atomic, c.Validate)
+	if err != nil {
+		h.logger.Error("Failed to run batch in memory",
+			zap.Error(err),
+			zap.String("handler", "BatchProducts (Memory)"),
+			zap.Int("operation_count", len(req.Operations)),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to run batch"})
+	}
+
+	status, failed := describeBatchResults(req.Operations, results, committed)
+
+	h.logger.Info("Batch processed in memory",
+		zap.Bool("atomic", atomic),
+		zap.Bool("committed", committed),
+		zap.Int("operation_count", len(results)),
+		zap.Int("failed_count", failed),
+	)
+
+	return c.JSON(status, map[string]interface{}{
+		"atomic":    atomic,
+		"committed": committed,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+		"results":   results,
+	})
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// BulkUpdateProducts handles POST request to update all products matching a filter in memory
+func (h *ProductMemoryHandler) BulkUpdateProducts(c echo.Context) error {
+	// Parse filter parameters
+	filter, dryRun, status, err := parseBulkFilter(c)
+	if err != nil {
+		h.logger.Warn("Invalid bulk filter",
+			zap.Error(err),
+			zap.String("handler", "BulkUpdateProducts (Memory)"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	var req models.BulkUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind bulk update request",
+			zap.Error(err),
+			zap.String("handler", "BulkUpdateProducts (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	err = c.Validate(&req)
+	if err == nil {
+		err = req.Check()
+	}
+	if err != nil {
+		h.logger.Warn("Bulk update validation failed",
+			zap.Error(err),
+			zap.String("handler", "BulkUpdateProducts (Memory)"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Update products in memory
+	result, err := h.repo.BulkUpdate(c.Request().Context(), filter, &req, dryRun)
+	if errors.Is(err, repository.ErrInvalidProduct) {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	if err != nil {
+		h.logger.Error("Failed to bulk update products in memory",
+			zap.Error(err),
+			zap.String("handler", "BulkUpdateProducts (Memory)"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update products"})
+	}
+
+	h.logger.Info("Bulk update processed in memory",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("matched_count", result.Matched),
+		zap.Int("affected_count", result.Affected),
+	)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// BulkDeleteProducts handles POST request to soft-delete all products matching a filter from memory
+func (h *ProductMemoryHandler) BulkDeleteProducts(c echo.Context) error {
+	// Parse filter parameters
+	filter, dryRun, status, err := parseBulkFilter(c)
+	if err != nil {
+		h.logger.Warn("Invalid bulk filter",
+			zap.Error(err),
+			zap.String("handler", "BulkDeleteProducts (Memory)"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Delete products from memory
+	result, err := h.repo.BulkDelete(c.Request().Context(), filter, dryRun)
+	if err != nil {
+		h.logger.Error("Failed to bulk delete products from memory",
+			zap.Error(err),
+			zap.String("handler", "BulkDeleteProducts (Memory)"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete products"})
+	}
+
+	h.logger.Info("Bulk delete processed from memory",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("affected_count", result.Affected),
+	)
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CountLiveProducts counts the live products in memory, the rows that deleting all products
+// or generating more acts on
+func (h *ProductMemoryHandler) CountLiveProducts(c echo.Context) (int, error) {
+	return h.repo.Count(c.Request().Context(), models.ProductFilter{})
+}
+
+// CountBulkTargets counts the products in memory a bulk update or delete request would act on
+func (h *ProductMemoryHandler) CountBulkTargets(c echo.Context) (int, error) {
+	filter, _, status, err := parseBulkFilter(c)
+	if err != nil {
+		return 0, echo.NewHTTPError(status, err.Error())
+	}
+	return h.repo.Count(c.Request().Context(), filter)
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -58,6 +60,12 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 			zap.String("handler", "CreateProduct"),
 			zap.Any("product", product),
 		)
+		switch {
+		case errors.Is(err, repository.ErrDuplicateSKU):
+			return skuConflict(c, product.SKU)
+		case errors.Is(err, repository.ErrUnknownCategory), errors.Is(err, repository.ErrInvalidAttributes):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create product"})
 	}
 
@@ -66,6 +74,7 @@ func (h *ProductHandler) CreateProduct(c echo.Context) error {
 		zap.String("product_name", product.Name),
 	)
 
+	setProductETag(c, &product)
 	return c.JSON(http.StatusCreated, product)
 }
 
@@ -84,7 +93,21 @@ func (h *ProductHandler) GetProduct(c echo.Context) error {
 	}
 
 	// Retrieve product
-	product, err := h.repo.GetByID(c.Request().Context(), id)
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "GetProduct"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	var product *models.Product
+	if filter.IncludeDeleted {
+		product, err = h.repo.GetByIDWithDeleted(c.Request().Context(), id)
+	} else {
+		product, err = h.repo.GetByID(c.Request().Context(), id)
+	}
 	if err != nil {
 		h.logger.Error("Failed to retrieve product",
 			zap.Error(err),
@@ -94,11 +117,139 @@ func (h *ProductHandler) GetProduct(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
 	}
 
+	// Drafts and archived products are only shown to administrators
+	if !isStatusVisible(c, filter, product) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	}
+
+	// Report the price effective at the requested moment
+	if filter.AsOf != nil {
+		products := []models.Product{*product}
+		if err := h.repo.ApplyPricesAsOf(c.Request().Context(), products, *filter.AsOf); err != nil {
+			h.logger.Error("Failed to resolve historical price",
+				zap.Error(err),
+				zap.String("handler", "GetProduct"),
+				zap.String("product_id", id.String()),
+			)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product"})
+		}
+		product = &products[0]
+	}
+
+	// Embed related data; the version then no longer describes the whole response
+	if filter.Include.Any() {
+		products := []models.Product{*product}
+		if err := h.repo.IncludeRelated(c.Request().Context(), products, filter.Include); err != nil {
+			h.logger.Error("Failed to include related data",
+				zap.Error(err),
+				zap.String("handler", "GetProduct"),
+				zap.String("product_id", id.String()),
+			)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product"})
+		}
+		product = &products[0]
+	}
+
+	// Serve the name and description in the negotiated locale
+	if len(filter.Locales) > 0 {
+		products := []models.Product{*product}
+		if err := h.repo.ApplyTranslations(c.Request().Context(), products, filter.Locales); err != nil {
+			h.logger.Error("Failed to translate product",
+				zap.Error(err),
+				zap.String("handler", "GetProduct"),
+				zap.String("product_id", id.String()),
+			)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product"})
+		}
+		product = &products[0]
+	}
+	setContentLanguage(c, *product)
+
+	// Honour conditional GET when the version describes the whole response; a historical price
+	// is not part of the current version
+	plain := filter.AsOf == nil && !filter.Include.Any() && !isTranslated(product)
+	if ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch); ifNoneMatch != "" && plain && etagMatches(ifNoneMatch, productETag(product), true) {
+		setProductETag(c, product)
+		return c.NoContent(http.StatusNotModified)
+	}
+
 	h.logger.Info("Product retrieved successfully",
 		zap.String("product_id", product.ID.String()),
 		zap.String("product_name", product.Name),
 	)
 
+	if filter.AsOf == nil {
+		setProductETag(c, product)
+	}
+	return c.JSON(http.StatusOK, product)
+}
+
+// GetProductBySKU handles GET request to retrieve a product by its SKU
+func (h *ProductHandler) GetProductBySKU(c echo.Context) error {
+	sku := c.Param("sku")
+	if !models.IsValidSKU(sku) {
+		h.logger.Warn("Invalid product SKU",
+			zap.String("handler", "GetProductBySKU"),
+			zap.String("input_sku", sku),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product SKU"})
+	}
+
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "GetProductBySKU"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Retrieve product
+	product, err := h.repo.GetBySKU(c.Request().Context(), sku)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product by SKU",
+			zap.Error(err),
+			zap.String("handler", "GetProductBySKU"),
+			zap.String("sku", sku),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product"})
+	}
+
+	// Drafts and archived products are only shown to administrators
+	if !isStatusVisible(c, filter, product) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	}
+
+	// Serve the name and description in the negotiated locale
+	if chain := models.LocaleChain(c.Request().Header.Get(headerAcceptLanguage)); len(chain) > 0 {
+		products := []models.Product{*product}
+		if err := h.repo.ApplyTranslations(c.Request().Context(), products, chain); err != nil {
+			h.logger.Error("Failed to translate product",
+				zap.Error(err),
+				zap.String("handler", "GetProductBySKU"),
+				zap.String("sku", sku),
+			)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product"})
+		}
+		product = &products[0]
+	}
+	setContentLanguage(c, *product)
+
+	// Honour conditional GET when the version describes the whole response
+	if ifNoneMatch := c.Request().Header.Get(headerIfNoneMatch); ifNoneMatch != "" && !isTranslated(product) && etagMatches(ifNoneMatch, productETag(product), true) {
+		setProductETag(c, product)
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	h.logger.Info("Product retrieved by SKU successfully",
+		zap.String("product_id", product.ID.String()),
+		zap.String("sku", sku),
+	)
+
+	setProductETag(c, product)
 	return c.JSON(http.StatusOK, product)
 }
 
@@ -115,8 +266,18 @@ func (h *ProductHandler) ListProducts(c echo.Context) error {
 		pageSize = 10
 	}
 
+	// Parse filter parameters
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "ListProducts"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
 	// Retrieve products
-	products, err := h.repo.List(c.Request().Context(), page, pageSize)
+	products, err := h.repo.List(c.Request().Context(), page, pageSize, filter)
 	if err != nil {
 		h.logger.Error("Failed to retrieve products",
 			zap.Error(err),
@@ -128,7 +289,7 @@ func (h *ProductHandler) ListProducts(c echo.Context) error {
 	}
 
 	// Get total count for pagination metadata
-	totalCount, err := h.repo.Count(c.Request().Context())
+	totalCount, err := h.repo.Count(c.Request().Context(), filter)
 	if err != nil {
 		h.logger.Warn("Failed to retrieve total product count",
 			zap.Error(err),
@@ -144,6 +305,7 @@ func (h *ProductHandler) ListProducts(c echo.Context) error {
 		zap.Int("returned_count", len(products)),
 	)
 
+	setContentLanguage(c, products...)
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"products":   products,
 		"page":       page,
@@ -154,8 +316,18 @@ func (h *ProductHandler) ListProducts(c echo.Context) error {
 
 // GetAllProducts handles GET request to retrieve all products without pagination
 func (h *ProductHandler) GetAllProducts(c echo.Context) error {
+	// Parse filter parameters
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "GetAllProducts"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
 	// Retrieve all products
-	products, err := h.repo.GetAll(c.Request().Context()) // Arbitrary large page size
+	products, err := h.repo.GetAll(c.Request().Context(), filter) // Arbitrary large page size
 	if err != nil {
 		h.logger.Error("Failed to retrieve all products",
 			zap.Error(err),
@@ -170,6 +342,7 @@ func (h *ProductHandler) GetAllProducts(c echo.Context) error {
 		zap.Int("total_count", totalCount),
 	)
 
+	setContentLanguage(c, products...)
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"products":   products,
 		"page":       1,
@@ -178,7 +351,7 @@ func (h *ProductHandler) GetAllProducts(c echo.Context) error {
 	})
 }
 
-// UpdateProduct handles PUT request to update a product
+// UpdateProduct handles PUT request to replace a product, creating it when absent
 func (h *ProductHandler) UpdateProduct(c echo.Context) error {
 	// Parse product ID from URL
 	idStr := c.Param("id")
@@ -214,34 +387,206 @@ func (h *ProductHandler) UpdateProduct(c echo.Context) error {
 		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
 	}
 
-	// Update product
-	if err := h.repo.Update(c.Request().Context(), id, &req); err != nil {
-		h.logger.Error("Failed to update product",
+	// Evaluate If-Match precondition
+	expectedVersion, handled, err := h.resolveIfMatch(c, id, "UpdateProduct")
+	if handled {
+		return err
+	}
+
+	// Create-only when the client asks for it with If-None-Match: *
+	createOnly := strings.TrimSpace(c.Request().Header.Get(headerIfNoneMatch)) == "*"
+
+	// Replace product, creating it under the client-supplied ID when absent
+	product, created, err := h.repo.Put(c.Request().Context(), id, &req, expectedVersion, createOnly)
+	if err != nil {
+		h.logger.Error("Failed to put product",
 			zap.Error(err),
 			zap.String("handler", "UpdateProduct"),
 			zap.String("product_id", id.String()),
 			zap.Any("request", req),
 		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+		case errors.Is(err, repository.ErrProductExists):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product already exists"})
+		case errors.Is(err, repository.ErrProductDeleted):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Product is deleted, restore it first"})
+		case errors.Is(err, repository.ErrDuplicateSKU):
+			return skuConflict(c, req.SKU)
+		case errors.Is(err, repository.ErrUnknownCategory), errors.Is(err, repository.ErrInvalidAttributes):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update product"})
 	}
 
-	// Retrieve updated product
-	updatedProduct, err := h.repo.GetByID(c.Request().Context(), id)
+	setProductETag(c, product)
+	if created {
+		h.logger.Info("Product created successfully",
+			zap.String("product_id", product.ID.String()),
+			zap.String("product_name", product.Name),
+		)
+		return c.JSON(http.StatusCreated, product)
+	}
+
+	h.logger.Info("Product updated successfully",
+		zap.String("product_id", product.ID.String()),
+		zap.String("product_name", product.Name),
+	)
+	return c.JSON(http.StatusOK, product)
+}
+
+// UpsertProduct handles POST request to create or replace a product keyed by its SKU
+func (h *ProductHandler) UpsertProduct(c echo.Context) error {
+	var req models.ProductRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind upsert request",
+			zap.Error(err),
+			zap.String("handler", "UpsertProduct"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request; the SKU is the upsert key
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Product upsert validation failed",
+			zap.Error(err),
+			zap.String("handler", "UpsertProduct"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	if req.SKU == "" {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "sku is required for upsert"})
+	}
+
+	// Upsert product
+	product, created, err := h.repo.UpsertBySKU(c.Request().Context(), &req)
 	if err != nil {
-		h.logger.Error("Failed to retrieve updated product",
+		h.logger.Error("Failed to upsert product",
 			zap.Error(err),
-			zap.String("handler", "UpdateProduct"),
+			zap.String("handler", "UpsertProduct"),
+			zap.String("sku", req.SKU),
+		)
+		switch {
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Product was modified concurrently, please retry"})
+		case errors.Is(err, repository.ErrUnknownCategory), errors.Is(err, repository.ErrInvalidAttributes):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to upsert product"})
+	}
+
+	h.logger.Info("Product upserted successfully",
+		zap.String("product_id", product.ID.String()),
+		zap.String("sku", product.SKU),
+		zap.Bool("created", created),
+	)
+
+	setProductETag(c, product)
+	if created {
+		return c.JSON(http.StatusCreated, product)
+	}
+	return c.JSON(http.StatusOK, product)
+}
+
+// PatchProduct handles PATCH request to partially update a product
+func (h *ProductHandler) PatchProduct(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "PatchProduct"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Evaluate If-Match precondition
+	expectedVersion, handled, err := h.resolveIfMatch(c, id, "PatchProduct")
+	if handled {
+		return err
+	}
+
+	// Retrieve the product the patch applies to
+	current, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product",
+			zap.Error(err),
+			zap.String("handler", "PatchProduct"),
 			zap.String("product_id", id.String()),
 		)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve updated product"})
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
 	}
 
-	h.logger.Info("Product updated successfully",
-		zap.String("product_id", updatedProduct.ID.String()),
-		zap.String("product_name", updatedProduct.Name),
+	// Apply patch document
+	req, status, err := applyProductPatch(c, current)
+	if err != nil {
+		h.logger.Warn("Failed to apply product patch",
+			zap.Error(err),
+			zap.String("handler", "PatchProduct"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Validate patched product
+	if err := c.Validate(req); err != nil {
+		h.logger.Warn("Product patch validation failed",
+			zap.Error(err),
+			zap.String("handler", "PatchProduct"),
+			zap.String("product_id", id.String()),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Persist changed fields only, conditioned on the version the patch was applied to
+	changes := req.Changes(current)
+	if err := h.repo.Patch(c.Request().Context(), id, changes, current.Version); err != nil {
+		h.logger.Error("Failed to patch product",
+			zap.Error(err),
+			zap.String("handler", "PatchProduct"),
+			zap.String("product_id", id.String()),
+			zap.Any("changes", changes),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVersionMismatch) && expectedVersion != 0:
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Product was modified concurrently, please retry"})
+		case errors.Is(err, repository.ErrDuplicateSKU):
+			return skuConflict(c, req.SKU)
+		case errors.Is(err, repository.ErrUnknownCategory), errors.Is(err, repository.ErrInvalidAttributes):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to patch product"})
+	}
+
+	// Retrieve patched product
+	patchedProduct, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve patched product",
+			zap.Error(err),
+			zap.String("handler", "PatchProduct"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve patched product"})
+	}
+
+	h.logger.Info("Product patched successfully",
+		zap.String("product_id", patchedProduct.ID.String()),
+		zap.Int("changed_fields", len(changes)),
 	)
 
-	return c.JSON(http.StatusOK, updatedProduct)
+	setProductETag(c, patchedProduct)
+	return c.JSON(http.StatusOK, patchedProduct)
 }
 
 // DeleteProduct handles DELETE request to remove a product
@@ -258,13 +603,25 @@ func (h *ProductHandler) DeleteProduct(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
 	}
 
+	// Evaluate If-Match precondition
+	expectedVersion, handled, err := h.resolveIfMatch(c, id, "DeleteProduct")
+	if handled {
+		return err
+	}
+
 	// Delete product
-	if err := h.repo.Delete(c.Request().Context(), id); err != nil {
+	if err := h.repo.Delete(c.Request().Context(), id, expectedVersion); err != nil {
 		h.logger.Error("Failed to delete product",
 			zap.Error(err),
 			zap.String("handler", "DeleteProduct"),
 			zap.String("product_id", id.String()),
 		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete product"})
 	}
 
@@ -275,6 +632,77 @@ func (h *ProductHandler) DeleteProduct(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"message": "Product deleted successfully"})
 }
 
+// RestoreProduct handles POST request to restore a soft-deleted product
+func (h *ProductHandler) RestoreProduct(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "RestoreProduct"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Evaluate If-Match precondition against the tombstone
+	var expectedVersion int64
+	if ifMatch := c.Request().Header.Get(headerIfMatch); ifMatch != "" {
+		current, err := h.repo.GetByIDWithDeleted(c.Request().Context(), id)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product does not exist"})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check precondition"})
+		}
+		if !etagMatches(ifMatch, productETag(current), false) {
+			setProductETag(c, current)
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+		}
+		expectedVersion = current.Version
+	}
+
+	// Restore product
+	if err := h.repo.Restore(c.Request().Context(), id, expectedVersion); err != nil {
+		h.logger.Error("Failed to restore product",
+			zap.Error(err),
+			zap.String("handler", "RestoreProduct"),
+			zap.String("product_id", id.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrProductNotDeleted):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Product is not deleted"})
+		case errors.Is(err, repository.ErrDuplicateSKU):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Another product now holds this product's SKU"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to restore product"})
+	}
+
+	// Retrieve restored product
+	restoredProduct, err := h.repo.GetByID(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve restored product",
+			zap.Error(err),
+			zap.String("handler", "RestoreProduct"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve restored product"})
+	}
+
+	h.logger.Info("Product restored successfully",
+		zap.String("product_id", restoredProduct.ID.String()),
+		zap.String("product_name", restoredProduct.Name),
+	)
+
+	setProductETag(c, restoredProduct)
+	return c.JSON(http.StatusOK, restoredProduct)
+}
+
 // BulkGenerateProducts handles POST request to generate random products
 func (h *ProductHandler) BulkGenerateProducts(c echo.Context) error {
 	// Parse number of products to generate
@@ -298,7 +726,7 @@ func (h *ProductHandler) BulkGenerateProducts(c echo.Context) error {
 	}
 
 	// Get total count after generation
-	totalCount, err := h.repo.Count(c.Request().Context())
+	totalCount, err := h.repo.Count(c.Request().Context(), models.ProductFilter{})
 	if err != nil {
 		h.logger.Warn("Failed to retrieve total product count after bulk generation",
 			zap.Error(err),
@@ -339,7 +767,17 @@ func (h *ProductHandler) DeleteAllProducts(c echo.Context) error {
 
 // GetProductCount handles GET request to retrieve the total number of products
 func (h *ProductHandler) GetProductCount(c echo.Context) error {
-	totalCount, err := h.repo.Count(c.Request().Context())
+	// Parse filter parameters
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "GetProductCount"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	totalCount, err := h.repo.Count(c.Request().Context(), filter)
 	if err != nil {
 		h.logger.Error("Failed to retrieve product count",
 			zap.Error(err),
@@ -356,3 +794,45 @@ func (h *ProductHandler) GetProductCount(c echo.Context) error {
 		"totalCount": totalCount,
 	})
 }
+
+// resolveIfMatch evaluates the If-Match precondition of a write request.
+// It returns the version the write must be conditioned on (0 when no precondition was sent);
+// handled is true when a response has already been written.
+func (h *ProductHandler) resolveIfMatch(c echo.Context, id uuid.UUID, handlerName string) (expectedVersion int64, handled bool, err error) {
+	ifMatch := c.Request().Header.Get(headerIfMatch)
+	if ifMatch == "" {
+		return 0, false, nil
+	}
+
+	// Without a current representation no entity tag matches, not even * (RFC 9110 §13.1.1)
+	current, err := h.repo.GetByID(c.Request().Context(), id)
+	if errors.Is(err, repository.ErrProductNotFound) {
+		h.logger.Warn("Product precondition failed",
+			zap.String("handler", handlerName),
+			zap.String("product_id", id.String()),
+			zap.String("if_match", ifMatch),
+		)
+		return 0, true, c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product does not exist"})
+	}
+	if err != nil {
+		h.logger.Error("Failed to retrieve product for precondition check",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+			zap.String("product_id", id.String()),
+		)
+		return 0, true, c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to check precondition"})
+	}
+
+	if !etagMatches(ifMatch, productETag(current), false) {
+		h.logger.Warn("Product precondition failed",
+			zap.String("handler", handlerName),
+			zap.String("product_id", id.String()),
+			zap.String("if_match", ifMatch),
+			zap.Int64("current_version", current.Version),
+		)
+		setProductETag(c, current)
+		return 0, true, c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+	}
+
+	return current.Version, false, nil
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/repository"
+)
+
+// GetProductHistory handles GET request to retrieve the audit trail of a product from memory
+func (h *ProductMemoryHandler) GetProductHistory(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "GetProductHistory (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve history from memory
+	entries, err := h.repo.GetHistory(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product history from memory",
+			zap.Error(err),
+			zap.String("handler", "GetProductHistory (Memory)"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product history"})
+	}
+
+	if len(entries) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product history not found"})
+	}
+
+	h.logger.Info("Product history retrieved successfully from memory",
+		zap.String("product_id", id.String()),
+		zap.Int("entry_count", len(entries)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"history":   entries,
+	})
+}
+
+// GetProductHistoryVersion handles GET request to retrieve a product as it was at a given version from memory
+func (h *ProductMemoryHandler) GetProductHistoryVersion(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "GetProductHistoryVersion (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Parse version from URL
+	version, err := strconv.ParseInt(c.Param("version"), 10, 64)
+	if err != nil || version < 1 {
+		h.logger.Warn("Invalid product version",
+			zap.String("handler", "GetProductHistoryVersion (Memory)"),
+			zap.String("input_version", c.Param("version")),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product version"})
+	}
+
+	// Retrieve history entry from memory
+	entry, err := h.repo.GetHistoryVersion(c.Request().Context(), id, version)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product history version from memory",
+			zap.Error(err),
+			zap.String("handler", "GetProductHistoryVersion (Memory)"),
+			zap.String("product_id", id.String()),
+			zap.Int64("version", version),
+		)
+		if errors.Is(err, repository.ErrHistoryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product version not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product history"})
+	}
+
+	h.logger.Info("Product history version retrieved successfully from memory",
+		zap.String("product_id", id.String()),
+		zap.Int64("version", version),
+	)
+
+	return c.JSON(http.StatusOK, entry)
+}
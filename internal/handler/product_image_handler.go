@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// UploadImage handles multipart POST request to add an image to a product
+func (h *ProductHandler) UploadImage(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "UploadImage"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	upload, status, err := readImageUpload(c, id)
+	if err != nil {
+		h.logger.Warn("Rejected image upload",
+			zap.Error(err),
+			zap.String("handler", "UploadImage"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Save image
+	image := upload.image
+	if err := h.repo.AddImage(c.Request().Context(), &image, upload.content, upload.thumbnail); err != nil {
+		h.logger.Error("Failed to add image",
+			zap.Error(err),
+			zap.String("handler", "UploadImage"),
+			zap.String("product_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add image"})
+	}
+
+	h.logger.Info("Image added successfully",
+		zap.String("product_id", id.String()),
+		zap.String("image_id", image.ID.String()),
+		zap.String("content_type", image.ContentType),
+		zap.Int64("size", image.Size),
+	)
+
+	return c.JSON(http.StatusCreated, image)
+}
+
+// ListImages handles GET request to list the images of a product
+func (h *ProductHandler) ListImages(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "ListImages"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve images
+	images, err := h.repo.ListImages(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve images",
+			zap.Error(err),
+			zap.String("handler", "ListImages"),
+			zap.String("product_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve images"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"images":    images,
+	})
+}
+
+// GetImageContent handles GET request to download an image of a product
+func (h *ProductHandler) GetImageContent(c echo.Context) error {
+	return h.streamImage(c, "GetImageContent", false)
+}
+
+// GetImageThumbnail handles GET request to download the thumbnail of an image
+func (h *ProductHandler) GetImageThumbnail(c echo.Context) error {
+	return h.streamImage(c, "GetImageThumbnail", true)
+}
+
+// streamImage writes the content or the thumbnail of an image to the response
+func (h *ProductHandler) streamImage(c echo.Context, handlerName string, thumbnail bool) error {
+	id, imageID, err := parseImageIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or image ID",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Open image content
+	image, content, err := h.repo.OpenImage(c.Request().Context(), id, imageID, thumbnail)
+	if err != nil {
+		h.logger.Error("Failed to open image",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+			zap.String("product_id", id.String()),
+			zap.String("image_id", imageID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrImageNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve image"})
+	}
+	defer content.Close()
+
+	contentType := image.ContentType
+	if thumbnail {
+		contentType = image.ThumbnailContentType
+	} else {
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(image.Size, 10))
+	}
+	return c.Stream(http.StatusOK, contentType, content)
+}
+
+// ReorderImages handles PUT request to change the display order of the images of a product
+func (h *ProductHandler) ReorderImages(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "ReorderImages"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req models.ImageOrderRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind image order request",
+			zap.Error(err),
+			zap.String("handler", "ReorderImages"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Image order validation failed",
+			zap.Error(err),
+			zap.String("handler", "ReorderImages"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Reorder images
+	images, err := h.repo.ReorderImages(c.Request().Context(), id, req.ImageIDs)
+	if err != nil {
+		h.logger.Warn("Failed to reorder images",
+			zap.Error(err),
+			zap.String("handler", "ReorderImages"),
+			zap.String("product_id", id.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrInvalidImageOrder):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to reorder images"})
+	}
+
+	h.logger.Info("Images reordered successfully",
+		zap.String("product_id", id.String()),
+		zap.Int("image_count", len(images)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"images":    images,
+	})
+}
+
+// SetPrimaryImage handles POST request to make an image the primary image of its product
+func (h *ProductHandler) SetPrimaryImage(c echo.Context) error {
+	id, imageID, err := parseImageIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or image ID",
+			zap.Error(err),
+			zap.String("handler", "SetPrimaryImage"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Update primary image
+	image, err := h.repo.SetPrimaryImage(c.Request().Context(), id, imageID)
+	if err != nil {
+		h.logger.Error("Failed to set primary image",
+			zap.Error(err),
+			zap.String("handler", "SetPrimaryImage"),
+			zap.String("product_id", id.String()),
+			zap.String("image_id", imageID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrImageNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to set primary image"})
+	}
+
+	h.logger.Info("Primary image set successfully",
+		zap.String("product_id", id.String()),
+		zap.String("image_id", imageID.String()),
+	)
+
+	return c.JSON(http.StatusOK, image)
+}
+
+// DeleteImage handles DELETE request to remove an image of a product
+func (h *ProductHandler) DeleteImage(c echo.Context) error {
+	id, imageID, err := parseImageIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or image ID",
+			zap.Error(err),
+			zap.String("handler", "DeleteImage"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Delete image
+	if err := h.repo.DeleteImage(c.Request().Context(), id, imageID); err != nil {
+		h.logger.Error("Failed to delete image",
+			zap.Error(err),
+			zap.String("handler", "DeleteImage"),
+			zap.String("product_id", id.String()),
+			zap.String("image_id", imageID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrImageNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Image not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete image"})
+	}
+
+	h.logger.Info("Image deleted successfully",
+		zap.String("product_id", id.String()),
+		zap.String("image_id", imageID.String()),
+	)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Image deleted successfully"})
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetProductPrices handles GET request to retrieve the price history of a product
+func (h *ProductHandler) GetProductPrices(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "GetProductPrices"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve price series
+	prices, err := h.repo.GetPriceHistory(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product prices",
+			zap.Error(err),
+			zap.String("handler", "GetProductPrices"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product prices"})
+	}
+
+	if len(prices) == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+	}
+
+	h.logger.Info("Product prices retrieved successfully",
+		zap.String("product_id", id.String()),
+		zap.Int("price_count", len(prices)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"prices":    prices,
+	})
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// CreatePriceSchedule handles POST request to schedule a price change for a product
+func (h *ProductHandler) CreatePriceSchedule(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "CreatePriceSchedule"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req models.PriceScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind price schedule request",
+			zap.Error(err),
+			zap.String("handler", "CreatePriceSchedule"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Price schedule validation failed",
+			zap.Error(err),
+			zap.String("handler", "CreatePriceSchedule"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Save schedule
+	schedule := req.ToPriceSchedule(id)
+	if err := h.repo.CreateSchedule(c.Request().Context(), &schedule); err != nil {
+		h.logger.Error("Failed to create price schedule",
+			zap.Error(err),
+			zap.String("handler", "CreatePriceSchedule"),
+			zap.String("product_id", id.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrScheduleOverlap):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Price schedule overlaps an existing schedule"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create price schedule"})
+	}
+
+	h.logger.Info("Price schedule created successfully",
+		zap.String("product_id", id.String()),
+		zap.String("schedule_id", schedule.ID.String()),
+		zap.Time("starts_at", schedule.StartsAt),
+	)
+
+	return c.JSON(http.StatusCreated, schedule)
+}
+
+// ListPriceSchedules handles GET request to list the price schedules of a product
+func (h *ProductHandler) ListPriceSchedules(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "ListPriceSchedules"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve schedules
+	schedules, err := h.repo.ListSchedules(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve price schedules",
+			zap.Error(err),
+			zap.String("handler", "ListPriceSchedules"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve price schedules"})
+	}
+
+	h.logger.Info("Price schedules listed successfully",
+		zap.String("product_id", id.String()),
+		zap.Int("returned_count", len(schedules)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"schedules": schedules,
+	})
+}
+
+// CancelPriceSchedule handles DELETE request to cancel a price schedule
+func (h *ProductHandler) CancelPriceSchedule(c echo.Context) error {
+	// Parse product and schedule IDs from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "CancelPriceSchedule"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	scheduleIDStr := c.Param("scheduleId")
+	scheduleID, err := uuid.Parse(scheduleIDStr)
+	if err != nil {
+		h.logger.Warn("Invalid price schedule ID",
+			zap.Error(err),
+			zap.String("handler", "CancelPriceSchedule"),
+			zap.String("input_id", scheduleIDStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid price schedule ID"})
+	}
+
+	// Cancel schedule
+	schedule, err := h.repo.CancelSchedule(c.Request().Context(), id, scheduleID)
+	if err != nil {
+		h.logger.Error("Failed to cancel price schedule",
+			zap.Error(err),
+			zap.String("handler", "CancelPriceSchedule"),
+			zap.String("product_id", id.String()),
+			zap.String("schedule_id", scheduleID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrScheduleNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Price schedule not found"})
+		case errors.Is(err, repository.ErrScheduleClosed):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Price schedule has already finished"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to cancel price schedule"})
+	}
+
+	h.logger.Info("Price schedule cancelled successfully",
+		zap.String("product_id", id.String()),
+		zap.String("schedule_id", scheduleID.String()),
+	)
+
+	return c.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+)
+
+// SearchProducts handles GET request to search products by the words of their name and description
+func (h *ProductHandler) SearchProducts(c echo.Context) error {
+	q := strings.TrimSpace(c.QueryParam("q"))
+	if q == "" || utf8.RuneCountInString(q) > models.MaxSearchQueryLength {
+		h.logger.Warn("Invalid search query",
+			zap.String("handler", "SearchProducts"),
+			zap.String("query", q),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "q is required and must be at most " + strconv.Itoa(models.MaxSearchQueryLength) + " characters",
+		})
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	pageSize, _ := strconv.Atoi(c.QueryParam("pageSize"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	// Parse filter parameters
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "SearchProducts"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Search products
+	hits, totalCount, err := h.repo.Search(c.Request().Context(), q, page, pageSize, filter)
+	if err != nil {
+		h.logger.Error("Failed to search products",
+			zap.Error(err),
+			zap.String("handler", "SearchProducts"),
+			zap.String("query", q),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search products"})
+	}
+
+	h.logger.Info("Products searched successfully",
+		zap.String("query", q),
+		zap.Int("page", page),
+		zap.Int("page_size", pageSize),
+		zap.Int("total_count", totalCount),
+		zap.Int("returned_count", len(hits)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"query":      q,
+		"results":    hits,
+		"page":       page,
+		"pageSize":   pageSize,
+		"totalCount": totalCount,
+	})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// TransitionProduct handles POST request to move a product in memory to another lifecycle state
+func (h *ProductMemoryHandler) TransitionProduct(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "TransitionProduct (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req models.TransitionRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind transition request",
+			zap.Error(err),
+			zap.String("handler", "TransitionProduct (Memory)"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Transition validation failed",
+			zap.Error(err),
+			zap.String("handler", "TransitionProduct (Memory)"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Evaluate If-Match precondition
+	expectedVersion, handled, err := h.resolveIfMatch(c, id, "TransitionProduct (Memory)")
+	if handled {
+		return err
+	}
+
+	// Move product to the requested state
+	product, err := h.repo.Transition(c.Request().Context(), id, req.Status, expectedVersion)
+	if err != nil {
+		h.logger.Warn("Failed to transition product in memory",
+			zap.Error(err),
+			zap.String("handler", "TransitionProduct (Memory)"),
+			zap.String("product_id", id.String()),
+			zap.String("status", req.Status),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVersionMismatch):
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "Product has been modified"})
+		case errors.Is(err, repository.ErrInvalidTransition):
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to transition product"})
+	}
+
+	h.logger.Info("Product transitioned successfully in memory",
+		zap.String("product_id", id.String()),
+		zap.String("status", product.Status),
+		zap.Int64("version", product.Version),
+	)
+
+	setProductETag(c, product)
+	return c.JSON(http.StatusOK, product)
+}
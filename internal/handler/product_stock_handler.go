@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// AdjustStock handles POST request to add to or remove from the on-hand stock of a product
+func (h *ProductHandler) AdjustStock(c echo.Context) error {
+	return h.moveStock(c, "AdjustStock", models.StockMovementAdjust, h.repo.AdjustStock)
+}
+
+// ReserveStock handles POST request to reserve available stock of a product
+func (h *ProductHandler) ReserveStock(c echo.Context) error {
+	return h.moveStock(c, "ReserveStock", models.StockMovementReserve, h.repo.ReserveStock)
+}
+
+// ReleaseStock handles POST request to release reserved stock of a product
+func (h *ProductHandler) ReleaseStock(c echo.Context) error {
+	return h.moveStock(c, "ReleaseStock", models.StockMovementRelease, h.repo.ReleaseStock)
+}
+
+// moveStock parses a stock request and applies it with the given repository operation
+func (h *ProductHandler) moveStock(c echo.Context, handlerName, kind string,
+	move func(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error)) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req models.StockRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind stock request",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request; only adjustments may be negative
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Stock request validation failed",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+	if kind != models.StockMovementAdjust && req.Quantity < 0 {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "quantity must be positive"})
+	}
+
+	// Apply movement
+	level, err := move(c.Request().Context(), id, req.Variant(), req.Quantity, req.Reason)
+	if err != nil {
+		h.logger.Warn("Failed to move stock",
+			zap.Error(err),
+			zap.String("handler", handlerName),
+			zap.String("product_id", id.String()),
+			zap.Int64("quantity", req.Quantity),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVariantNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		case errors.Is(err, repository.ErrInsufficientStock):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Insufficient stock"})
+		case errors.Is(err, repository.ErrInsufficientReserved):
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Cannot release more stock than is reserved"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update stock"})
+	}
+
+	h.logger.Info("Stock updated successfully",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", level.VariantID.String()),
+		zap.String("kind", kind),
+		zap.Int64("quantity", req.Quantity),
+		zap.Int64("on_hand", level.OnHand),
+		zap.Int64("reserved", level.Reserved),
+	)
+
+	return c.JSON(http.StatusOK, level)
+}
+
+// GetStock handles GET request to retrieve the stock levels of a product
+func (h *ProductHandler) GetStock(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "GetStock"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve stock levels
+	levels, err := h.repo.GetStock(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve stock",
+			zap.Error(err),
+			zap.String("handler", "GetStock"),
+			zap.String("product_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve stock"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"levels":    levels,
+	})
+}
+
+// GetStockMovements handles GET request to retrieve the stock ledger of a product
+func (h *ProductHandler) GetStockMovements(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "GetStockMovements"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve stock movements
+	movements, err := h.repo.GetStockMovements(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve stock movements",
+			zap.Error(err),
+			zap.String("handler", "GetStockMovements"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve stock movements"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"movements": movements,
+	})
+}
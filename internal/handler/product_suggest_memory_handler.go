@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/pkg/search"
+)
+
+// SuggestProducts handles GET request to suggest product names for a typed prefix from memory
+func (h *ProductMemoryHandler) SuggestProducts(c echo.Context) error {
+	prefix := c.QueryParam("prefix")
+	if search.NormalizeName(prefix) == "" || utf8.RuneCountInString(prefix) > models.MaxSuggestPrefixLength {
+		h.logger.Warn("Invalid suggestion prefix",
+			zap.String("handler", "SuggestProducts (Memory)"),
+			zap.String("prefix", prefix),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "prefix must contain a letter or digit and be at most " + strconv.Itoa(models.MaxSuggestPrefixLength) + " characters",
+		})
+	}
+
+	limit := models.DefaultSuggestLimit
+	if value := c.QueryParam("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > models.MaxSuggestLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "limit must be between 1 and " + strconv.Itoa(models.MaxSuggestLimit),
+			})
+		}
+		limit = parsed
+	}
+
+	// Parse filter parameters
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "SuggestProducts (Memory)"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Retrieve suggestions from memory
+	suggestions, err := h.repo.Suggest(c.Request().Context(), prefix, limit, filter)
+	if err != nil {
+		h.logger.Error("Failed to retrieve suggestions from memory",
+			zap.Error(err),
+			zap.String("handler", "SuggestProducts (Memory)"),
+			zap.String("prefix", prefix),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve suggestions"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"prefix":      prefix,
+		"suggestions": suggestions,
+	})
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// GetProductTags handles GET request to count the products carrying each tag
+func (h *ProductHandler) GetProductTags(c echo.Context) error {
+	// Parse filter parameters so tag counts can act as facets of a product listing
+	filter, status, err := parseProductFilter(c, c.QueryParams())
+	if err != nil {
+		h.logger.Warn("Invalid product filter",
+			zap.Error(err),
+			zap.String("handler", "GetProductTags"),
+		)
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	// Retrieve tag counts
+	tags, err := h.repo.TagCounts(c.Request().Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to retrieve product tags",
+			zap.Error(err),
+			zap.String("handler", "GetProductTags"),
+		)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve product tags"})
+	}
+
+	h.logger.Info("Product tags retrieved successfully",
+		zap.Int("tag_count", len(tags)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"tags": tags,
+	})
+}
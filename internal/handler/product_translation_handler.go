@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// PutTranslation handles PUT request to create or replace the translation of a product
+func (h *ProductHandler) PutTranslation(c echo.Context) error {
+	id, locale, err := parseTranslationRoute(c)
+	if err != nil {
+		h.logger.Warn("Invalid product ID or locale",
+			zap.Error(err),
+			zap.String("handler", "PutTranslation"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var req models.TranslationRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind translation request",
+			zap.Error(err),
+			zap.String("handler", "PutTranslation"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Translation validation failed",
+			zap.Error(err),
+			zap.String("handler", "PutTranslation"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Save translation
+	translation := req.ToTranslation(id, locale)
+	created, err := h.repo.PutTranslation(c.Request().Context(), &translation)
+	if err != nil {
+		h.logger.Error("Failed to save translation",
+			zap.Error(err),
+			zap.String("handler", "PutTranslation"),
+			zap.String("product_id", id.String()),
+			zap.String("locale", locale),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save translation"})
+	}
+
+	h.logger.Info("Translation saved successfully",
+		zap.String("product_id", id.String()),
+		zap.String("locale", locale),
+		zap.Bool("created", created),
+	)
+
+	if created {
+		return c.JSON(http.StatusCreated, translation)
+	}
+	return c.JSON(http.StatusOK, translation)
+}
+
+// ListTranslations handles GET request to list the translations of a product
+func (h *ProductHandler) ListTranslations(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "ListTranslations"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve translations
+	translations, err := h.repo.ListTranslations(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve translations",
+			zap.Error(err),
+			zap.String("handler", "ListTranslations"),
+			zap.String("product_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve translations"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId":     id,
+		"defaultLocale": models.DefaultLocale,
+		"translations":  translations,
+	})
+}
+
+// GetTranslation handles GET request to retrieve the translation of a product in one locale
+func (h *ProductHandler) GetTranslation(c echo.Context) error {
+	id, locale, err := parseTranslationRoute(c)
+	if err != nil {
+		h.logger.Warn("Invalid product ID or locale",
+			zap.Error(err),
+			zap.String("handler", "GetTranslation"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Retrieve translation
+	translation, err := h.repo.GetTranslation(c.Request().Context(), id, locale)
+	if err != nil {
+		h.logger.Error("Failed to retrieve translation",
+			zap.Error(err),
+			zap.String("handler", "GetTranslation"),
+			zap.String("product_id", id.String()),
+			zap.String("locale", locale),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrTranslationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Translation not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve translation"})
+	}
+
+	return c.JSON(http.StatusOK, translation)
+}
+
+// DeleteTranslation handles DELETE request to remove the translation of a product in one locale
+func (h *ProductHandler) DeleteTranslation(c echo.Context) error {
+	id, locale, err := parseTranslationRoute(c)
+	if err != nil {
+		h.logger.Warn("Invalid product ID or locale",
+			zap.Error(err),
+			zap.String("handler", "DeleteTranslation"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Delete translation
+	if err := h.repo.DeleteTranslation(c.Request().Context(), id, locale); err != nil {
+		h.logger.Error("Failed to delete translation",
+			zap.Error(err),
+			zap.String("handler", "DeleteTranslation"),
+			zap.String("product_id", id.String()),
+			zap.String("locale", locale),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrTranslationNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Translation not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete translation"})
+	}
+
+	h.logger.Info("Translation deleted successfully",
+		zap.String("product_id", id.String()),
+		zap.String("locale", locale),
+	)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Translation deleted successfully"})
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// CreateVariant handles POST request to add a variant to a product
+func (h *ProductHandler) CreateVariant(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req models.VariantRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind variant request",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Variant validation failed",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Save variant
+	variant := req.ToVariant(id)
+	if err := h.repo.CreateVariant(c.Request().Context(), &variant); err != nil {
+		h.logger.Error("Failed to create variant",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant"),
+			zap.String("product_id", id.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrDuplicateVariantSKU):
+			return variantSKUConflict(c, variant.SKU)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create variant"})
+	}
+
+	h.logger.Info("Variant created successfully",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", variant.ID.String()),
+		zap.String("sku", variant.SKU),
+	)
+
+	return c.JSON(http.StatusCreated, variant)
+}
+
+// ListVariants handles GET request to list the variants of a product
+func (h *ProductHandler) ListVariants(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "ListVariants"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve variants
+	variants, err := h.repo.ListVariants(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve variants",
+			zap.Error(err),
+			zap.String("handler", "ListVariants"),
+			zap.String("product_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve variants"})
+	}
+
+	h.logger.Info("Variants listed successfully",
+		zap.String("product_id", id.String()),
+		zap.Int("returned_count", len(variants)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"variants":  variants,
+	})
+}
+
+// GetVariant handles GET request to retrieve a single variant of a product
+func (h *ProductHandler) GetVariant(c echo.Context) error {
+	id, variantID, err := parseVariantIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or variant ID",
+			zap.Error(err),
+			zap.String("handler", "GetVariant"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Retrieve variant
+	variant, err := h.repo.GetVariant(c.Request().Context(), id, variantID)
+	if err != nil {
+		h.logger.Error("Failed to retrieve variant",
+			zap.Error(err),
+			zap.String("handler", "GetVariant"),
+			zap.String("product_id", id.String()),
+			zap.String("variant_id", variantID.String()),
+		)
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve variant"})
+	}
+
+	return c.JSON(http.StatusOK, variant)
+}
+
+// UpdateVariant handles PUT request to replace a variant of a product
+func (h *ProductHandler) UpdateVariant(c echo.Context) error {
+	id, variantID, err := parseVariantIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or variant ID",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var req models.VariantRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind variant request",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant"),
+			zap.String("variant_id", variantID.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Variant validation failed",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Update variant
+	variant, err := h.repo.UpdateVariant(c.Request().Context(), id, variantID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update variant",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant"),
+			zap.String("product_id", id.String()),
+			zap.String("variant_id", variantID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVariantNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		case errors.Is(err, repository.ErrDuplicateVariantSKU):
+			return variantSKUConflict(c, req.SKU)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update variant"})
+	}
+
+	h.logger.Info("Variant updated successfully",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", variantID.String()),
+	)
+
+	return c.JSON(http.StatusOK, variant)
+}
+
+// DeleteVariant handles DELETE request to remove a variant of a product
+func (h *ProductHandler) DeleteVariant(c echo.Context) error {
+	id, variantID, err := parseVariantIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or variant ID",
+			zap.Error(err),
+			zap.String("handler", "DeleteVariant"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Delete variant
+	if err := h.repo.DeleteVariant(c.Request().Context(), id, variantID); err != nil {
+		h.logger.Error("Failed to delete variant",
+			zap.Error(err),
+			zap.String("handler", "DeleteVariant"),
+			zap.String("product_id", id.String()),
+			zap.String("variant_id", variantID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVariantNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete variant"})
+	}
+
+	h.logger.Info("Variant deleted successfully",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", variantID.String()),
+	)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Variant deleted successfully"})
+}
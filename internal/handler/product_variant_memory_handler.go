@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// CreateVariant handles POST request to add a variant to a product in memory
+func (h *ProductMemoryHandler) CreateVariant(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	var req models.VariantRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind variant request",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant (Memory)"),
+			zap.String("product_id", id.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Variant validation failed",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant (Memory)"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Save variant in memory
+	variant := req.ToVariant(id)
+	if err := h.repo.CreateVariant(c.Request().Context(), &variant); err != nil {
+		h.logger.Error("Failed to create variant in memory",
+			zap.Error(err),
+			zap.String("handler", "CreateVariant (Memory)"),
+			zap.String("product_id", id.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrDuplicateVariantSKU):
+			return variantSKUConflict(c, variant.SKU)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create variant"})
+	}
+
+	h.logger.Info("Variant created successfully in memory",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", variant.ID.String()),
+		zap.String("sku", variant.SKU),
+	)
+
+	return c.JSON(http.StatusCreated, variant)
+}
+
+// ListVariants handles GET request to list the variants of a product from memory
+func (h *ProductMemoryHandler) ListVariants(c echo.Context) error {
+	// Parse product ID from URL
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.Warn("Invalid product ID",
+			zap.Error(err),
+			zap.String("handler", "ListVariants (Memory)"),
+			zap.String("input_id", idStr),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Retrieve variants from memory
+	variants, err := h.repo.ListVariants(c.Request().Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to retrieve variants from memory",
+			zap.Error(err),
+			zap.String("handler", "ListVariants (Memory)"),
+			zap.String("product_id", id.String()),
+		)
+		if errors.Is(err, repository.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve variants"})
+	}
+
+	h.logger.Info("Variants listed successfully from memory",
+		zap.String("product_id", id.String()),
+		zap.Int("returned_count", len(variants)),
+	)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"productId": id,
+		"variants":  variants,
+	})
+}
+
+// GetVariant handles GET request to retrieve a single variant of a product from memory
+func (h *ProductMemoryHandler) GetVariant(c echo.Context) error {
+	id, variantID, err := parseVariantIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or variant ID",
+			zap.Error(err),
+			zap.String("handler", "GetVariant (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Retrieve variant from memory
+	variant, err := h.repo.GetVariant(c.Request().Context(), id, variantID)
+	if err != nil {
+		h.logger.Error("Failed to retrieve variant from memory",
+			zap.Error(err),
+			zap.String("handler", "GetVariant (Memory)"),
+			zap.String("product_id", id.String()),
+			zap.String("variant_id", variantID.String()),
+		)
+		if errors.Is(err, repository.ErrVariantNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve variant"})
+	}
+
+	return c.JSON(http.StatusOK, variant)
+}
+
+// UpdateVariant handles PUT request to replace a variant of a product in memory
+func (h *ProductMemoryHandler) UpdateVariant(c echo.Context) error {
+	id, variantID, err := parseVariantIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or variant ID",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	var req models.VariantRequest
+	if err := c.Bind(&req); err != nil {
+		h.logger.Warn("Failed to bind variant request",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant (Memory)"),
+			zap.String("variant_id", variantID.String()),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	// Validate request
+	if err := c.Validate(&req); err != nil {
+		h.logger.Warn("Variant validation failed",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant (Memory)"),
+			zap.Any("request", req),
+		)
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	}
+
+	// Update variant in memory
+	variant, err := h.repo.UpdateVariant(c.Request().Context(), id, variantID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update variant in memory",
+			zap.Error(err),
+			zap.String("handler", "UpdateVariant (Memory)"),
+			zap.String("product_id", id.String()),
+			zap.String("variant_id", variantID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVariantNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		case errors.Is(err, repository.ErrDuplicateVariantSKU):
+			return variantSKUConflict(c, req.SKU)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update variant"})
+	}
+
+	h.logger.Info("Variant updated successfully in memory",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", variantID.String()),
+	)
+
+	return c.JSON(http.StatusOK, variant)
+}
+
+// DeleteVariant handles DELETE request to remove a variant of a product from memory
+func (h *ProductMemoryHandler) DeleteVariant(c echo.Context) error {
+	id, variantID, err := parseVariantIDs(c)
+	if err != nil {
+		h.logger.Warn("Invalid product or variant ID",
+			zap.Error(err),
+			zap.String("handler", "DeleteVariant (Memory)"),
+		)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// Delete variant from memory
+	if err := h.repo.DeleteVariant(c.Request().Context(), id, variantID); err != nil {
+		h.logger.Error("Failed to delete variant from memory",
+			zap.Error(err),
+			zap.String("handler", "DeleteVariant (Memory)"),
+			zap.String("product_id", id.String()),
+			zap.String("variant_id", variantID.String()),
+		)
+		switch {
+		case errors.Is(err, repository.ErrProductNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Product not found"})
+		case errors.Is(err, repository.ErrVariantNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Variant not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete variant"})
+	}
+
+	h.logger.Info("Variant deleted successfully from memory",
+		zap.String("product_id", id.String()),
+		zap.String("variant_id", variantID.String()),
+	)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Variant deleted successfully"})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// skuConflict writes the 409 response for a write that would duplicate a live product's SKU
+func skuConflict(c echo.Context, sku string) error {
+	return c.JSON(http.StatusConflict, map[string]string{
+		"error": "A product with this SKU already exists",
+		"sku":   sku,
+	})
+}
+
+// variantSKUConflict writes the 409 response for a write that would duplicate a variant's SKU
+func variantSKUConflict(c echo.Context, sku string) error {
+	return c.JSON(http.StatusConflict, map[string]string{
+		"error": "A variant with this SKU already exists",
+		"sku":   sku,
+	})
+}
+
+// parseVariantIDs reads the product and variant IDs of a variant route
+func parseVariantIDs(c echo.Context) (uuid.UUID, uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid product ID")
+	}
+	variantID, err := uuid.Parse(c.Param("variantId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid variant ID")
+	}
+	return id, variantID, nil
+}
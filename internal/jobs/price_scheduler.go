@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"product-service/pkg/logger"
+	"product-service/pkg/requestctx"
+)
+
+// PriceSchedulerActor attributes scheduled price changes in the audit trail
+const PriceSchedulerActor = "price-scheduler"
+
+// ScheduleApplier applies and reverts price schedules that are due
+type ScheduleApplier interface {
+	ApplyDueSchedules(ctx context.Context, now time.Time) (int, error)
+}
+
+// StartPriceScheduler advances due price schedules on every tick until ctx is cancelled.
+// Appliers are keyed by a store name used in log output.
+func StartPriceScheduler(ctx context.Context, interval time.Duration, appliers map[string]ScheduleApplier) {
+	log := logger.GetLogger()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ctx = requestctx.WithActor(ctx, PriceSchedulerActor)
+
+	log.Info("Price scheduler started",
+		zap.Duration("interval", interval),
+	)
+
+	// Catch up on schedules that fell due while the service was down
+	runPriceSchedules(ctx, log, appliers)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Price scheduler stopped")
+			return
+		case <-ticker.C:
+			runPriceSchedules(ctx, log, appliers)
+		}
+	}
+}
+
+// runPriceSchedules performs one scheduling pass over every store
+func runPriceSchedules(ctx context.Context, log *zap.Logger, appliers map[string]ScheduleApplier) {
+	now := time.Now()
+	for store, applier := range appliers {
+		processed, err := applier.ApplyDueSchedules(ctx, now)
+		if err != nil {
+			log.Error("Failed to apply price schedules",
+				zap.Error(err),
+				zap.String("store", store),
+			)
+		}
+		if processed > 0 {
+			log.Info("Applied price schedules",
+				zap.String("store", store),
+				zap.Int("processed_count", processed),
+			)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"product-service/pkg/logger"
+)
+
+// Purger permanently removes soft-deleted products
+type Purger interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// StartPurger hard-deletes tombstones older than retention on every tick until ctx is cancelled.
+// Purgers are keyed by a store name used in log output.
+func StartPurger(ctx context.Context, interval, retention time.Duration, purgers map[string]Purger) {
+	log := logger.GetLogger()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info("Soft-delete purger started",
+		zap.Duration("interval", interval),
+		zap.Duration("retention", retention),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Soft-delete purger stopped")
+			return
+		case <-ticker.C:
+			before := time.Now().Add(-retention)
+			for store, purger := range purgers {
+				purged, err := purger.PurgeDeleted(ctx, before)
+				if err != nil {
+					log.Error("Failed to purge deleted products",
+						zap.Error(err),
+						zap.String("store", store),
+					)
+					continue
+				}
+				if purged > 0 {
+					log.Info("Purged deleted products",
+						zap.String("store", store),
+						zap.Int64("purged_count", purged),
+						zap.Time("deleted_before", before),
+					)
+				}
+			}
+		}
+	}
+}
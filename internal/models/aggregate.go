@@ -0,0 +1,157 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Dimensions products can be grouped by in aggregates
+const (
+	GroupByStatus     = "status"
+	GroupByCategory   = "category"
+	GroupByNamePrefix = "namePrefix"
+)
+
+// Intervals of the created_at histogram
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Defaults and bounds of aggregate options
+const (
+	DefaultPriceBuckets     = 10
+	MaxPriceBuckets         = 100
+	DefaultNamePrefixLength = 1
+	MaxNamePrefixLength     = 10
+)
+
+// DefaultPercentiles are the price percentiles reported when none are requested
+var DefaultPercentiles = []float64{50, 90, 99}
+
+// AggregateOptions select the statistics of a product aggregate
+type AggregateOptions struct {
+	// Percentiles of the price to report, each between 0 and 100
+	Percentiles []float64
+
+	// PriceBuckets is the number of equal-width buckets between the lowest and highest price
+	PriceBuckets int
+
+	// CreatedInterval is the bucket width of the created_at histogram
+	CreatedInterval string
+
+	// GroupBy lists the dimensions to count products by
+	GroupBy []string
+
+	// NamePrefixLength is the number of leading name characters GroupByNamePrefix groups by
+	NamePrefixLength int
+}
+
+// ProductAggregate holds counts and statistics over the products matching a filter
+type ProductAggregate struct {
+	Count            int                     `json:"count"`
+	Price            *PriceStats             `json:"price"`
+	PriceHistogram   []PriceBucket           `json:"priceHistogram"`
+	CreatedHistogram []TimeBucket            `json:"createdHistogram"`
+	Groups           map[string][]GroupCount `json:"groups,omitempty"`
+}
+
+// PriceStats summarizes the prices of the aggregated products. Percentiles interpolate
+// between neighbouring prices and are keyed like "p90".
+type PriceStats struct {
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Avg         float64            `json:"avg"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// PriceBucket counts the products priced from From up to To. The last bucket includes To.
+type PriceBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// TimeBucket counts the products created in the interval starting at Start (UTC)
+type TimeBucket struct {
+	Start time.Time `json:"start" db:"start"`
+	Count int       `json:"count" db:"count"`
+}
+
+// GroupCount is the number of products sharing a value of a group-by dimension
+type GroupCount struct {
+	Key   string `json:"key" db:"key"`
+	Count int    `json:"count" db:"count"`
+}
+
+// PercentileKey names a percentile in PriceStats.Percentiles
+func PercentileKey(percentile float64) string {
+	return "p" + strconv.FormatFloat(percentile, 'f', -1, 64)
+}
+
+// Percentile interpolates the percentile (0-100) of sorted values linearly, like the
+// percentile_cont aggregate of Postgres
+func Percentile(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := percentile / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+// NewPriceHistogram lays out empty equal-width buckets from min to max. Equal bounds
+// make a single bucket.
+func NewPriceHistogram(min, max float64, buckets int) []PriceBucket {
+	if min == max {
+		buckets = 1
+	}
+	width := (max - min) / float64(buckets)
+	histogram := make([]PriceBucket, buckets)
+	for i := range histogram {
+		histogram[i] = PriceBucket{From: min + float64(i)*width, To: min + float64(i+1)*width}
+	}
+	histogram[buckets-1].To = max
+	return histogram
+}
+
+// PriceBucketIndex returns the index of the bucket of NewPriceHistogram(min, max, buckets)
+// that holds price
+func PriceBucketIndex(price, min, max float64, buckets int) int {
+	if min == max {
+		return 0
+	}
+	index := int((price - min) / (max - min) * float64(buckets))
+	if index >= buckets {
+		return buckets - 1
+	}
+	return index
+}
+
+// TruncateTime returns the start of the interval t falls in, in UTC. Weeks start on Monday.
+func TruncateTime(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case IntervalWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// SortGroupCounts orders group counts largest first, then by key
+func SortGroupCounts(groups []GroupCount) {
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Key < groups[j].Key
+	})
+}
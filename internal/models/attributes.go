@@ -0,0 +1,263 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Attribute value types a category schema can require
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+)
+
+// Attribute filter operators
+const (
+	AttributeOpEq  = "eq"
+	AttributeOpNe  = "ne"
+	AttributeOpGt  = "gt"
+	AttributeOpGte = "gte"
+	AttributeOpLt  = "lt"
+	AttributeOpLte = "lte"
+)
+
+// attributeKeyPattern accepts 1-64 lowercase letters, digits and underscores, starting with a letter
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Attributes holds the free-form attributes of a product, stored as a JSONB object.
+// Values are strings, numbers or booleans.
+type Attributes map[string]interface{}
+
+// Value implements driver.Valuer; nil attributes are stored as an empty object
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (a *Attributes) Scan(src interface{}) error {
+	return scanJSON(src, a)
+}
+
+// Equal reports whether both attribute sets hold the same values; nil equals empty
+func (a Attributes) Equal(other Attributes) bool {
+	if len(a) == 0 && len(other) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, other)
+}
+
+// AttributeSpec describes one attribute of a category schema
+type AttributeSpec struct {
+	Type     string   `json:"type" validate:"required,oneof=string number boolean"`
+	Required bool     `json:"required,omitempty"`
+	Enum     []string `json:"enum,omitempty" validate:"omitempty,dive,required"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+}
+
+// AttributeSchema maps attribute keys to their specs, stored as a JSONB object
+type AttributeSchema map[string]AttributeSpec
+
+// Value implements driver.Valuer; a nil schema is stored as an empty object
+func (s AttributeSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (s *AttributeSchema) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+// AttributeFilter is one attribute condition of the product list filter
+type AttributeFilter struct {
+	Key string
+	Op  string
+
+	// Value is the raw query value; Number holds it parsed for range operators
+	Value  string
+	Number float64
+}
+
+// ValidateAttributeKey implements the "attrkey" validation tag
+func ValidateAttributeKey(fl validator.FieldLevel) bool {
+	return IsValidAttributeKey(fl.Field().String())
+}
+
+// ValidateAttributeValue implements the "attrvalue" validation tag
+func ValidateAttributeValue(fl validator.FieldLevel) bool {
+	switch fl.Field().Kind() {
+	case reflect.String, reflect.Float64, reflect.Bool:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidAttributeKey reports whether s is a well-formed attribute key
+func IsValidAttributeKey(s string) bool {
+	return attributeKeyPattern.MatchString(s)
+}
+
+// ValidateAttributes checks product attributes against the schemas of its categories.
+// Attributes no schema mentions are accepted as they are.
+func ValidateAttributes(attributes Attributes, schemas []AttributeSchema) error {
+	var problems []string
+	for _, schema := range schemas {
+		keys := make([]string, 0, len(schema))
+		for key := range schema {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := schema[key].check(attributes[key]); err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s", key, err))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// check validates a single attribute value against the spec
+func (spec AttributeSpec) check(value interface{}) error {
+	if value == nil {
+		if spec.Required {
+			return errors.New("is required")
+		}
+		return nil
+	}
+
+	switch spec.Type {
+	case AttributeTypeString:
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if len(spec.Enum) > 0 {
+			for _, allowed := range spec.Enum {
+				if text == allowed {
+					return nil
+				}
+			}
+			return fmt.Errorf("must be one of %v", spec.Enum)
+		}
+	case AttributeTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return errors.New("must be a number")
+		}
+		if spec.Min != nil && number < *spec.Min {
+			return fmt.Errorf("must be at least %s", strconv.FormatFloat(*spec.Min, 'f', -1, 64))
+		}
+		if spec.Max != nil && number > *spec.Max {
+			return fmt.Errorf("must be at most %s", strconv.FormatFloat(*spec.Max, 'f', -1, 64))
+		}
+	case AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an attribute value satisfies the filter condition.
+// Equality is typed: "100" matches the number 100 and "true" the boolean true.
+func (f AttributeFilter) Matches(attributes Attributes) bool {
+	value, present := attributes[f.Key]
+
+	switch f.Op {
+	case AttributeOpEq, AttributeOpNe:
+		equal := present && f.equals(value)
+		return equal == (f.Op == AttributeOpEq)
+	default:
+		number, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		switch f.Op {
+		case AttributeOpGt:
+			return number > f.Number
+		case AttributeOpGte:
+			return number >= f.Number
+		case AttributeOpLt:
+			return number < f.Number
+		case AttributeOpLte:
+			return number <= f.Number
+		}
+		return false
+	}
+}
+
+// NumberValue returns the filter value read as a finite number
+func (f AttributeFilter) NumberValue() (float64, bool) {
+	number, err := strconv.ParseFloat(f.Value, 64)
+	if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+		return 0, false
+	}
+	return number, true
+}
+
+// BoolValue returns the filter value read as a boolean
+func (f AttributeFilter) BoolValue() (bool, bool) {
+	flag, err := strconv.ParseBool(f.Value)
+	return flag, err == nil
+}
+
+// equals compares a stored value with the filter value by the stored value's type
+func (f AttributeFilter) equals(value interface{}) bool {
+	switch typed := value.(type) {
+	case string:
+		return typed == f.Value
+	case float64:
+		number, ok := f.NumberValue()
+		return ok && typed == number
+	case bool:
+		flag, ok := f.BoolValue()
+		return ok && typed == flag
+	}
+	return false
+}
+
+// scanJSON decodes a JSONB column into dest
+func scanJSON(src interface{}, dest interface{}) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("cannot scan %T into a JSON object", src)
+	}
+	return json.Unmarshal(data, dest)
+}
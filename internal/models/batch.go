@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Batch operation kinds
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpPatch  = "patch"
+	BatchOpDelete = "delete"
+)
+
+// MaxBatchOperations bounds the number of operations in one batch request
+const MaxBatchOperations = 100
+
+// BatchRequest represents the input of a batch of product writes; handlers bound the number of
+// operations by MaxBatchOperations before validating them
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required,min=1"`
+}
+
+// BatchOperation is one write of a batch. Create takes a product; update replaces the product
+// with ID by product; patch applies a JSON merge patch object (RFC 7396) or a JSON Patch array
+// (RFC 6902) to it; delete soft-deletes it. A non-zero Version makes update, patch and delete
+// conditional on the stored version.
+type BatchOperation struct {
+	Op      string          `json:"op"`
+	ID      uuid.UUID       `json:"id"`
+	Version int64           `json:"version"`
+	Product *ProductRequest `json:"product"`
+	Patch   json.RawMessage `json:"patch"`
+}
+
+// BatchResult is the outcome of one operation of a batch, in the order of the request.
+// Err is set by the repository; Status and Error describe it to the client.
+type BatchResult struct {
+	Index   int        `json:"index"`
+	Op      string     `json:"op"`
+	ID      *uuid.UUID `json:"id,omitempty"`
+	Status  int        `json:"status"`
+	Product *Product   `json:"product,omitempty"`
+	Error   string     `json:"error,omitempty"`
+	Err     error      `json:"-"`
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// BulkSampleSize bounds the number of product IDs a bulk operation reports
+const BulkSampleSize = 10
+
+// MaxProductTags bounds the number of tags a product carries, as ProductRequest validates
+const MaxProductTags = 20
+
+// BulkUpdateRequest represents a change applied to every product matching a filter
+type BulkUpdateRequest struct {
+	Price      *BulkPriceChange `json:"price"`
+	AddTags    []string         `json:"add_tags" validate:"omitempty,max=20,unique,dive,tag"`
+	RemoveTags []string         `json:"remove_tags" validate:"omitempty,max=20,unique,dive,tag"`
+}
+
+// BulkPriceChange sets a price, adds an amount to it or changes it by a percentage; exactly one must be given
+type BulkPriceChange struct {
+	Set     *float64 `json:"set" validate:"omitempty,min=0"`
+	Add     *float64 `json:"add"`
+	Percent *float64 `json:"percent" validate:"omitempty,gt=-100"`
+}
+
+// BulkResult reports the products a bulk operation affected or, on a dry run, would affect
+type BulkResult struct {
+	DryRun    bool        `json:"dryRun"`
+	Matched   int         `json:"matched"`
+	Affected  int         `json:"affected"`
+	SampleIDs []uuid.UUID `json:"sampleIds"`
+}
+
+// Add counts a product the operation affects, keeping its ID while the sample has room
+func (r *BulkResult) Add(id uuid.UUID) {
+	r.Affected++
+	if len(r.SampleIDs) < BulkSampleSize {
+		r.SampleIDs = append(r.SampleIDs, id)
+	}
+}
+
+// Check verifies that the update changes something and that its parts do not contradict each other
+func (u *BulkUpdateRequest) Check() error {
+	if u.Price == nil && len(u.AddTags) == 0 && len(u.RemoveTags) == 0 {
+		return errors.New("update must change price, add_tags or remove_tags")
+	}
+	if u.Price != nil {
+		given := 0
+		for _, part := range []*float64{u.Price.Set, u.Price.Add, u.Price.Percent} {
+			if part != nil {
+				given++
+			}
+		}
+		if given != 1 {
+			return errors.New("price must give exactly one of set, add or percent")
+		}
+	}
+	for _, tag := range u.AddTags {
+		for _, removed := range u.RemoveTags {
+			if tag == removed {
+				return fmt.Errorf("tag %q is both added and removed", tag)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply changes the product in place and reports whether anything changed. Prices are rounded
+// to cents; a price that would become negative or a tag list that would grow past MaxProductTags
+// entries is an error.
+func (u *BulkUpdateRequest) Apply(p *Product) (bool, error) {
+	changed := false
+
+	if u.Price != nil {
+		price := p.Price
+		switch {
+		case u.Price.Set != nil:
+			price = *u.Price.Set
+		case u.Price.Add != nil:
+			price += *u.Price.Add
+		default:
+			price *= 1 + *u.Price.Percent/100
+		}
+		price = math.Round(price*100) / 100
+		if price < 0 {
+			return false, fmt.Errorf("price of product %s would become negative", p.ID)
+		}
+		if price != p.Price {
+			p.Price = price
+			changed = true
+		}
+	}
+
+	// Build a new list so that the product's previous state keeps its own
+	tags := make(pq.StringArray, 0, len(p.Tags)+len(u.AddTags))
+	for _, tag := range p.Tags {
+		if !ContainsString(u.RemoveTags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range u.AddTags {
+		if !ContainsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxProductTags {
+		return false, fmt.Errorf("product %s would carry more than %d tags", p.ID, MaxProductTags)
+	}
+	if !equalStrings(tags, p.Tags) {
+		p.Tags = tags
+		changed = true
+	}
+	return changed, nil
+}
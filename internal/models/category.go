@@ -0,0 +1,95 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category represents a node of the product taxonomy
+type Category struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+
+	// AttributeSchema constrains the attributes of products in this category and its subcategories
+	AttributeSchema AttributeSchema `json:"attribute_schema" db:"attribute_schema"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CategoryRequest represents the input for creating/updating a category
+type CategoryRequest struct {
+	Name        string     `json:"name" validate:"required,min=2,max=255"`
+	Description string     `json:"description"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+
+	AttributeSchema AttributeSchema `json:"attribute_schema" validate:"omitempty,max=50,dive,keys,attrkey,endkeys,required"`
+}
+
+// CategoryNode is a category together with its subcategories
+type CategoryNode struct {
+	Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// ToCategory converts CategoryRequest to Category
+func (cr *CategoryRequest) ToCategory() Category {
+	now := time.Now()
+	return Category{
+		ID:          uuid.New(),
+		Name:        cr.Name,
+		Description: cr.Description,
+		ParentID:    cr.ParentID,
+
+		AttributeSchema: cr.attributeSchema(),
+
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ApplyTo copies the editable fields of the request onto an existing category
+func (cr *CategoryRequest) ApplyTo(c *Category) {
+	c.Name = cr.Name
+	c.Description = cr.Description
+	c.ParentID = cr.ParentID
+	c.AttributeSchema = cr.attributeSchema()
+}
+
+// attributeSchema returns the requested schema, never nil so categories always list an object
+func (cr *CategoryRequest) attributeSchema() AttributeSchema {
+	if cr.AttributeSchema == nil {
+		return AttributeSchema{}
+	}
+	return cr.AttributeSchema
+}
+
+// BuildCategoryTree arranges categories into trees rooted at the top-level categories.
+// Siblings are ordered by name.
+func BuildCategoryTree(categories []Category) []*CategoryNode {
+	sorted := make([]Category, len(categories))
+	copy(sorted, categories)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	nodes := make(map[uuid.UUID]*CategoryNode, len(sorted))
+	for _, category := range sorted {
+		nodes[category.ID] = &CategoryNode{Category: category, Children: []*CategoryNode{}}
+	}
+
+	roots := make([]*CategoryNode, 0)
+	for _, category := range sorted {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
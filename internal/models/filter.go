@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxNameFilterLength bounds the text of the name filter
+const MaxNameFilterLength = 255
+
+// ProductFilter narrows down which products list, count and export queries return
+type ProductFilter struct {
+	// IncludeDeleted also returns soft-deleted products
+	IncludeDeleted bool
+
+	// AsOf reports prices as they were effective at this moment instead of current prices
+	AsOf *time.Time
+
+	// CategoryID keeps products assigned to this category
+	CategoryID *uuid.UUID
+
+	// IncludeDescendants widens CategoryID to its whole subtree
+	IncludeDescendants bool
+
+	// Tags keeps products carrying all (TagModeAll) or any (TagModeAny) of these tags
+	Tags    []string
+	TagMode string
+
+	// Name keeps products whose name contains this text, ignoring case
+	Name string
+
+	// MinPrice and MaxPrice keep products whose current price lies within these inclusive bounds
+	MinPrice *float64
+	MaxPrice *float64
+
+	// Statuses keeps products in any of these lifecycle states; empty keeps all states
+	Statuses []string
+
+	// Include embeds variants, price ranges or images into the returned products
+	Include ProductIncludes
+
+	// Locales is the Accept-Language fallback chain used to translate names and descriptions.
+	// Its last entry is the default locale; empty leaves products untranslated.
+	Locales []string
+
+	// Attributes keeps products whose attributes satisfy every condition
+	Attributes []AttributeFilter
+}
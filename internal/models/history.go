@@ -0,0 +1,89 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// History actions recorded in the audit trail
+const (
+	HistoryActionCreate  = "create"
+	HistoryActionUpdate  = "update"
+	HistoryActionPatch   = "patch"
+	HistoryActionDelete  = "delete"
+	HistoryActionRestore = "restore"
+
+	// Lifecycle status changes
+	HistoryActionTransition = "transition"
+
+	// Price changes applied and reverted by the price scheduler
+	HistoryActionScheduleApply  = "schedule_apply"
+	HistoryActionScheduleRevert = "schedule_revert"
+)
+
+// ProductHistory is an audit trail entry describing one change to a product
+type ProductHistory struct {
+	ID        int64     `json:"id" db:"id"`
+	ProductID uuid.UUID `json:"product_id" db:"product_id"`
+	Version   int64     `json:"version" db:"version"`
+	Action    string    `json:"action" db:"action"`
+	Actor     string    `json:"actor" db:"actor"`
+	RequestID string    `json:"request_id" db:"request_id"`
+	Before    Snapshot  `json:"before" db:"before"`
+	After     Snapshot  `json:"after" db:"after"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Snapshot is a point-in-time copy of a product, stored as JSONB
+type Snapshot struct {
+	Product *Product
+}
+
+// NewSnapshot copies the product so later changes do not leak into the snapshot
+func NewSnapshot(product *Product) Snapshot {
+	if product == nil {
+		return Snapshot{}
+	}
+	productCopy := *product
+	return Snapshot{Product: &productCopy}
+}
+
+// MarshalJSON renders the snapshot as the product itself, or null
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Product)
+}
+
+// Value implements driver.Valuer
+func (s Snapshot) Value() (driver.Value, error) {
+	if s.Product == nil {
+		return nil, nil
+	}
+	return json.Marshal(s.Product)
+}
+
+// Scan implements sql.Scanner
+func (s *Snapshot) Scan(src interface{}) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		s.Product = nil
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("cannot scan %T into Snapshot", src)
+	}
+
+	var product Product
+	if err := json.Unmarshal(data, &product); err != nil {
+		return err
+	}
+	s.Product = &product
+	return nil
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductImage describes an image of a product. The image and its thumbnail live in blob
+// storage under the unexported keys.
+type ProductImage struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ProductID   uuid.UUID `json:"product_id" db:"product_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	Width       int       `json:"width" db:"width"`
+	Height      int       `json:"height" db:"height"`
+
+	// Position orders the images of a product, starting at 0
+	Position int  `json:"position" db:"position"`
+	Primary  bool `json:"primary" db:"is_primary"`
+
+	BlobKey              string    `json:"-" db:"blob_key"`
+	ThumbnailKey         string    `json:"-" db:"thumbnail_key"`
+	ThumbnailContentType string    `json:"-" db:"thumbnail_content_type"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+}
+
+// ImageOrderRequest lists every image of a product in its new order
+type ImageOrderRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids" validate:"required,min=1"`
+}
+
+// NewProductImage describes a new image of a product; the repository assigns its position
+func NewProductImage(productID uuid.UUID, filename, contentType string, size int64, width, height int, thumbnailType string) ProductImage {
+	id := uuid.New()
+	prefix := "products/" + productID.String() + "/images/" + id.String()
+	return ProductImage{
+		ID:                   id,
+		ProductID:            productID,
+		Filename:             filename,
+		ContentType:          contentType,
+		Size:                 size,
+		Width:                width,
+		Height:               height,
+		BlobKey:              prefix,
+		ThumbnailKey:         prefix + "-thumbnail",
+		ThumbnailContentType: thumbnailType,
+		CreatedAt:            time.Now(),
+	}
+}
+
+// BlobKeys returns the keys of the image and its thumbnail
+func (pi *ProductImage) BlobKeys() []string {
+	return []string{pi.BlobKey, pi.ThumbnailKey}
+}
+
+// AttachImages embeds images into the products they belong to
+func AttachImages(products []Product, images []ProductImage) {
+	byProduct := make(map[uuid.UUID][]ProductImage, len(products))
+	for _, img := range images {
+		byProduct[img.ProductID] = append(byProduct[img.ProductID], img)
+	}
+
+	for i := range products {
+		products[i].Images = byProduct[products[i].ID]
+		if products[i].Images == nil {
+			products[i].Images = []ProductImage{}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PricePoint is a price that was effective for a product over a period of time.
+// An open-ended point (no EffectiveTo) is the current price.
+type PricePoint struct {
+	ID            int64      `json:"id" db:"id"`
+	ProductID     uuid.UUID  `json:"product_id" db:"product_id"`
+	Price         float64    `json:"price" db:"price"`
+	EffectiveFrom time.Time  `json:"effective_from" db:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty" db:"effective_to"`
+}
+
+// EffectiveAt reports whether the price was in effect at the given moment
+func (pp *PricePoint) EffectiveAt(at time.Time) bool {
+	return !pp.EffectiveFrom.After(at) && (pp.EffectiveTo == nil || pp.EffectiveTo.After(at))
+}
@@ -1,37 +1,178 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // Product represents the product structure
 type Product struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name" validate:"required,min=3,max=255"`
-	Description string    `json:"description" db:"description"`
-	Price       float64   `json:"price" db:"price" validate:"required,min=0"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          uuid.UUID      `json:"id" db:"id"`
+	SKU         string         `json:"sku" db:"sku"`
+	Name        string         `json:"name" db:"name" validate:"required,min=3,max=255"`
+	Description string         `json:"description" db:"description"`
+	Price       float64        `json:"price" db:"price" validate:"required,min=0"`
+	CategoryIDs UUIDArray      `json:"category_ids" db:"category_ids"`
+	Tags        pq.StringArray `json:"tags" db:"tags"`
+	Attributes  Attributes     `json:"attributes" db:"attributes"`
+	Status      string         `json:"status" db:"status"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
+	Version     int64          `json:"version" db:"version"`
+	DeletedAt   *time.Time     `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Variants, PriceRange and Images are embedded on request and are not stored with the product
+	Variants   []ProductVariant `json:"variants,omitempty" db:"-"`
+	PriceRange *PriceRange      `json:"price_range,omitempty" db:"-"`
+	Images     []ProductImage   `json:"images,omitempty" db:"-"`
+
+	// Locale is the locale of Name and Description when the caller negotiated one through Accept-Language
+	Locale string `json:"locale,omitempty" db:"-"`
+
+	// PriceUnknown is set when no price was effective at the moment an asOf lookup asked for, such as
+	// before the product was created; the price is then reported as null
+	PriceUnknown bool `json:"-" db:"-"`
+}
+
+// MarshalJSON renders the product, with a null price when the price is unknown
+func (p Product) MarshalJSON() ([]byte, error) {
+	type product Product
+	if !p.PriceUnknown {
+		return json.Marshal(product(p))
+	}
+	return json.Marshal(struct {
+		product
+		Price *float64 `json:"price"`
+	}{product: product(p)})
 }
 
 // ProductRequest represents the input for creating/updating a product
 type ProductRequest struct {
-	Name        string  `json:"name" validate:"required,min=3,max=255"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,min=0"`
+	SKU         string     `json:"sku" validate:"omitempty,sku"`
+	Name        string     `json:"name" validate:"required,min=3,max=255"`
+	Description string     `json:"description"`
+	Price       float64    `json:"price" validate:"required,min=0"`
+	CategoryIDs UUIDArray  `json:"category_ids" validate:"omitempty,max=32,unique"`
+	Tags        []string   `json:"tags" validate:"omitempty,max=20,unique,dive,tag"`
+	Attributes  Attributes `json:"attributes" validate:"omitempty,max=50,dive,keys,attrkey,endkeys,attrvalue"`
 }
 
-// ToProduct converts ProductRequest to Product
+// ToProduct converts ProductRequest to a draft Product
 func (pr *ProductRequest) ToProduct() Product {
 	now := time.Now()
 	return Product{
 		ID:          uuid.New(),
+		SKU:         pr.SKU,
 		Name:        pr.Name,
 		Description: pr.Description,
 		Price:       pr.Price,
+		CategoryIDs: pr.categoryIDs(),
+		Tags:        pr.tags(),
+		Attributes:  pr.attributes(),
+		Status:      StatusDraft,
 		CreatedAt:   now,
 		UpdatedAt:   now,
+		Version:     1,
+	}
+}
+
+// ApplyTo copies the editable fields of the request onto an existing product
+func (pr *ProductRequest) ApplyTo(p *Product) {
+	p.SKU = pr.SKU
+	p.Name = pr.Name
+	p.Description = pr.Description
+	p.Price = pr.Price
+	p.CategoryIDs = pr.categoryIDs()
+	p.Tags = pr.tags()
+	p.Attributes = pr.attributes()
+}
+
+// categoryIDs returns the requested categories, never nil so products always list an array
+func (pr *ProductRequest) categoryIDs() UUIDArray {
+	if pr.CategoryIDs == nil {
+		return UUIDArray{}
+	}
+	return pr.CategoryIDs
+}
+
+// tags returns the requested tags, never nil so products always list an array
+func (pr *ProductRequest) tags() pq.StringArray {
+	if pr.Tags == nil {
+		return pq.StringArray{}
+	}
+	return pq.StringArray(pr.Tags)
+}
+
+// attributes returns the requested attributes, never nil so products always list an object
+func (pr *ProductRequest) attributes() Attributes {
+	if pr.Attributes == nil {
+		return Attributes{}
+	}
+	return pr.Attributes
+}
+
+// ToRequest converts Product back into its editable representation
+func (p *Product) ToRequest() ProductRequest {
+	return ProductRequest{
+		SKU:         p.SKU,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		CategoryIDs: p.CategoryIDs,
+		Tags:        p.Tags,
+		Attributes:  p.Attributes,
+	}
+}
+
+// Changes returns the columns whose values differ between the request and the stored product
+func (pr *ProductRequest) Changes(p *Product) map[string]interface{} {
+	changes := make(map[string]interface{})
+	if pr.SKU != p.SKU {
+		changes["sku"] = pr.SKU
+	}
+	if pr.Name != p.Name {
+		changes["name"] = pr.Name
+	}
+	if pr.Description != p.Description {
+		changes["description"] = pr.Description
+	}
+	if pr.Price != p.Price {
+		changes["price"] = pr.Price
+	}
+	if !pr.CategoryIDs.Equal(p.CategoryIDs) {
+		changes["category_ids"] = pr.categoryIDs()
+	}
+	if !equalStrings(pr.Tags, p.Tags) {
+		changes["tags"] = pr.tags()
+	}
+	if !pr.Attributes.Equal(p.Attributes) {
+		changes["attributes"] = pr.attributes()
+	}
+	return changes
+}
+
+// ContainsString reports whether the list holds s
+func ContainsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// equalStrings reports whether both lists hold the same strings in the same order; nil equals empty
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
+	return true
 }
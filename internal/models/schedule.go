@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Price schedule lifecycle states
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusExpired   = "expired"
+	ScheduleStatusCancelled = "cancelled"
+	ScheduleStatusFailed    = "failed"
+)
+
+// PriceSchedule is a price that takes effect at StartsAt and, if EndsAt is set, reverts afterwards
+type PriceSchedule struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ProductID     uuid.UUID  `json:"product_id" db:"product_id"`
+	Price         float64    `json:"price" db:"price"`
+	StartsAt      time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at,omitempty" db:"ends_at"`
+	Status        string     `json:"status" db:"status"`
+	PreviousPrice *float64   `json:"previous_price,omitempty" db:"previous_price"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty" db:"applied_at"`
+	RevertedAt    *time.Time `json:"reverted_at,omitempty" db:"reverted_at"`
+	Attempts      int        `json:"attempts,omitempty" db:"attempts"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	RetryAt       *time.Time `json:"retry_at,omitempty" db:"retry_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// PriceScheduleRequest represents the input for scheduling a price change
+type PriceScheduleRequest struct {
+	Price    float64    `json:"price" validate:"required,min=0"`
+	StartsAt time.Time  `json:"starts_at" validate:"required"`
+	EndsAt   *time.Time `json:"ends_at,omitempty" validate:"omitempty,gtfield=StartsAt"`
+}
+
+// ToPriceSchedule converts PriceScheduleRequest to a pending PriceSchedule for the product
+func (r *PriceScheduleRequest) ToPriceSchedule(productID uuid.UUID) PriceSchedule {
+	now := time.Now()
+	return PriceSchedule{
+		ID:        uuid.New(),
+		ProductID: productID,
+		Price:     r.Price,
+		StartsAt:  r.StartsAt,
+		EndsAt:    r.EndsAt,
+		Status:    ScheduleStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Overlaps reports whether two schedules cover a common moment; open-ended schedules never end
+func (s *PriceSchedule) Overlaps(other *PriceSchedule) bool {
+	startsBeforeOtherEnds := other.EndsAt == nil || s.StartsAt.Before(*other.EndsAt)
+	otherStartsBeforeEnd := s.EndsAt == nil || other.StartsAt.Before(*s.EndsAt)
+	return startsBeforeOtherEnds && otherStartsBeforeEnd
+}
+
+// IsOpen reports whether the schedule still has work left for the scheduler
+func (s *PriceSchedule) IsOpen() bool {
+	return s.Status == ScheduleStatusPending || s.Status == ScheduleStatusActive
+}
@@ -0,0 +1,39 @@
+package models
+
+import "github.com/google/uuid"
+
+// MaxSearchQueryLength bounds the length of a full-text search query
+const MaxSearchQueryLength = 256
+
+// SearchDescriptionWords is the length in words of the description fragment shown with a
+// search result
+const SearchDescriptionWords = 35
+
+// SearchHit is a product matching a full-text search with its relevance
+type SearchHit struct {
+	Product    Product          `json:"product"`
+	Score      float64          `json:"score"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// SearchHighlights holds the HTML-escaped name and description of a search hit with matched
+// words wrapped in <mark> tags. The description is cut to a fragment around the first match.
+type SearchHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Bounds of the autocomplete endpoint
+const (
+	MaxSuggestPrefixLength = 64
+	DefaultSuggestLimit    = 10
+	MaxSuggestLimit        = 50
+)
+
+// Suggestion is a product name suggested for a prefix typed into a search box. The score is
+// the trigram similarity of the prefix to the name.
+type Suggestion struct {
+	ID    uuid.UUID `json:"id" db:"id"`
+	Name  string    `json:"name" db:"name"`
+	Score float64   `json:"score" db:"score"`
+}
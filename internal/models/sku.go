@@ -0,0 +1,20 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// skuPattern accepts 1-64 letters, digits, dots, dashes and underscores, starting with a letter or digit
+var skuPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// ValidateSKU implements the "sku" validation tag
+func ValidateSKU(fl validator.FieldLevel) bool {
+	return IsValidSKU(fl.Field().String())
+}
+
+// IsValidSKU reports whether s is a well-formed stock keeping unit
+func IsValidSKU(s string) bool {
+	return skuPattern.MatchString(s)
+}
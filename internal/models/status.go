@@ -0,0 +1,41 @@
+package models
+
+// Product lifecycle states
+const (
+	StatusDraft        = "draft"
+	StatusActive       = "active"
+	StatusArchived     = "archived"
+	StatusDiscontinued = "discontinued"
+)
+
+// productTransitions lists the states each state may move to
+var productTransitions = map[string][]string{
+	StatusDraft:        {StatusActive, StatusArchived},
+	StatusActive:       {StatusArchived, StatusDiscontinued},
+	StatusArchived:     {StatusActive, StatusDraft},
+	StatusDiscontinued: {StatusArchived},
+}
+
+// PublicStatuses are the states callers without administrator access may list
+var PublicStatuses = []string{StatusActive, StatusDiscontinued}
+
+// TransitionRequest represents the input for moving a product to another lifecycle state
+type TransitionRequest struct {
+	Status string `json:"status" validate:"required,oneof=draft active archived discontinued"`
+}
+
+// IsValidStatus reports whether s is a product lifecycle state
+func IsValidStatus(s string) bool {
+	_, ok := productTransitions[s]
+	return ok
+}
+
+// AllowedTransitions returns the states a product in the given state may move to
+func AllowedTransitions(from string) []string {
+	return productTransitions[from]
+}
+
+// CanTransition reports whether a product may move from one state to another
+func CanTransition(from, to string) bool {
+	return ContainsString(productTransitions[from], to)
+}
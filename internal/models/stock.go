@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stock movement kinds recorded in the ledger
+const (
+	StockMovementAdjust  = "adjust"
+	StockMovementReserve = "reserve"
+	StockMovementRelease = "release"
+)
+
+// StockLevel is the stock of a product, or of one of its variants. Product-level stock has
+// the nil variant ID.
+type StockLevel struct {
+	ProductID uuid.UUID `json:"product_id" db:"product_id"`
+	VariantID uuid.UUID `json:"variant_id" db:"variant_id"`
+	OnHand    int64     `json:"on_hand" db:"on_hand"`
+	Reserved  int64     `json:"reserved" db:"reserved"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Available returns the stock that can still be reserved
+func (s *StockLevel) Available() int64 {
+	return s.OnHand - s.Reserved
+}
+
+// MarshalJSON renders the stock level together with the available quantity
+func (s StockLevel) MarshalJSON() ([]byte, error) {
+	type stockLevel StockLevel
+	return json.Marshal(struct {
+		stockLevel
+		Available int64 `json:"available"`
+	}{stockLevel(s), s.Available()})
+}
+
+// StockMovement is a ledger entry of one change to a stock level
+type StockMovement struct {
+	ID        int64     `json:"id" db:"id"`
+	ProductID uuid.UUID `json:"product_id" db:"product_id"`
+	VariantID uuid.UUID `json:"variant_id" db:"variant_id"`
+	Kind      string    `json:"kind" db:"kind"`
+
+	// Quantity is the signed change to on-hand stock for adjustments, or the amount reserved or released
+	Quantity int64 `json:"quantity" db:"quantity"`
+
+	// OnHand and Reserved are the stock level after the movement
+	OnHand   int64 `json:"on_hand" db:"on_hand"`
+	Reserved int64 `json:"reserved" db:"reserved"`
+
+	Reason    string    `json:"reason" db:"reason"`
+	Actor     string    `json:"actor" db:"actor"`
+	RequestID string    `json:"request_id" db:"request_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// StockRequest represents the input for adjusting, reserving or releasing stock
+type StockRequest struct {
+	// VariantID targets the stock of a variant instead of the product itself
+	VariantID *uuid.UUID `json:"variant_id"`
+	Quantity  int64      `json:"quantity" validate:"required"`
+	Reason    string     `json:"reason" validate:"max=255"`
+}
+
+// Variant returns the targeted variant ID, or the nil ID for product-level stock
+func (sr *StockRequest) Variant() uuid.UUID {
+	if sr.VariantID == nil {
+		return uuid.Nil
+	}
+	return *sr.VariantID
+}
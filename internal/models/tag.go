@@ -0,0 +1,32 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Tag match modes of the product list filter
+const (
+	TagModeAll = "all"
+	TagModeAny = "any"
+)
+
+// tagPattern accepts 1-32 lowercase letters, digits and dashes, starting with a letter or digit
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// TagCount is the number of products carrying a tag
+type TagCount struct {
+	Tag   string `json:"tag" db:"tag"`
+	Count int    `json:"count" db:"count"`
+}
+
+// ValidateTag implements the "tag" validation tag
+func ValidateTag(fl validator.FieldLevel) bool {
+	return IsValidTag(fl.Field().String())
+}
+
+// IsValidTag reports whether s is a well-formed product tag
+func IsValidTag(s string) bool {
+	return tagPattern.MatchString(s)
+}
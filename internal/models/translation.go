@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the locale of the name and description stored on products themselves
+const DefaultLocale = "en"
+
+// ProductTranslation holds the name and description of a product in one locale
+type ProductTranslation struct {
+	ProductID   uuid.UUID `json:"product_id" db:"product_id"`
+	Locale      string    `json:"locale" db:"locale"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TranslationRequest represents the input for creating/replacing a translation.
+// Its fields follow the rules of the same fields of ProductRequest.
+type TranslationRequest struct {
+	Name        string `json:"name" validate:"required,min=3,max=255"`
+	Description string `json:"description"`
+}
+
+// ToTranslation converts TranslationRequest to a translation of the product in the locale
+func (tr *TranslationRequest) ToTranslation(productID uuid.UUID, locale string) ProductTranslation {
+	now := time.Now()
+	return ProductTranslation{
+		ProductID:   productID,
+		Locale:      locale,
+		Name:        tr.Name,
+		Description: tr.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// CanonicalLocale parses a BCP 47 language tag such as "de-at" and returns its canonical form ("de-AT")
+func CanonicalLocale(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return "", errors.New("invalid locale")
+	}
+	return tag.String(), nil
+}
+
+// LocaleChain expands an Accept-Language header into the locales to try in order. Each
+// preferred locale is followed by its more general parents ("de-AT" by "de"), and the chain
+// ends at the default locale, which every product has. It returns nil for a missing or
+// malformed header.
+func LocaleChain(acceptLanguage string) []string {
+	if acceptLanguage == "" {
+		return nil
+	}
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil
+	}
+
+	chain := make([]string, 0, len(tags)+1)
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for ; tag != language.Und; tag = tag.Parent() {
+			locale := tag.String()
+			if locale == DefaultLocale {
+				// Nothing after the default locale can be served
+				return append(chain, DefaultLocale)
+			}
+			if !seen[locale] {
+				seen[locale] = true
+				chain = append(chain, locale)
+			}
+		}
+	}
+	return append(chain, DefaultLocale)
+}
+
+// LocalizeProducts replaces the name and description of each product with its translation
+// in the first locale of the chain that has one. The last locale of the chain is the default
+// locale, served by the product itself. Each product reports the locale it was served in.
+func LocalizeProducts(products []Product, translations []ProductTranslation, chain []string) {
+	if len(chain) == 0 {
+		return
+	}
+
+	byProduct := make(map[uuid.UUID]map[string]ProductTranslation, len(products))
+	for _, translation := range translations {
+		if byProduct[translation.ProductID] == nil {
+			byProduct[translation.ProductID] = make(map[string]ProductTranslation)
+		}
+		byProduct[translation.ProductID][translation.Locale] = translation
+	}
+
+	for i := range products {
+		products[i].Locale = chain[len(chain)-1]
+		for _, locale := range chain[:len(chain)-1] {
+			translation, ok := byProduct[products[i].ID][locale]
+			if !ok {
+				continue
+			}
+			products[i].Name = translation.Name
+			if translation.Description != "" {
+				products[i].Description = translation.Description
+			}
+			products[i].Locale = locale
+			break
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// UUIDArray is a list of UUIDs stored as a Postgres UUID[] column
+type UUIDArray []uuid.UUID
+
+// Value implements driver.Valuer; a nil array is stored as an empty one
+func (a UUIDArray) Value() (driver.Value, error) {
+	ids := make([]string, len(a))
+	for i, id := range a {
+		ids[i] = id.String()
+	}
+	return "{" + strings.Join(ids, ",") + "}", nil
+}
+
+// Scan implements sql.Scanner
+func (a *UUIDArray) Scan(src interface{}) error {
+	var ids pq.StringArray
+	if err := ids.Scan(src); err != nil {
+		return err
+	}
+
+	parsed := make(UUIDArray, len(ids))
+	for i, id := range ids {
+		value, err := uuid.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid uuid in array: %w", err)
+		}
+		parsed[i] = value
+	}
+	*a = parsed
+	return nil
+}
+
+// Contains reports whether id is in the array
+func (a UUIDArray) Contains(id uuid.UUID) bool {
+	for _, value := range a {
+		if value == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal reports whether both arrays hold the same UUIDs in the same order; nil equals empty
+func (a UUIDArray) Equal(other UUIDArray) bool {
+	if len(a) != len(other) {
+		return false
+	}
+	for i := range a {
+		if a[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProductVariant is a sellable version of a product, such as one size or color, with its own SKU and price
+type ProductVariant struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ProductID  uuid.UUID  `json:"product_id" db:"product_id"`
+	SKU        string     `json:"sku" db:"sku"`
+	Name       string     `json:"name" db:"name"`
+	Price      float64    `json:"price" db:"price"`
+	Attributes Attributes `json:"attributes" db:"attributes"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// VariantRequest represents the input for creating/updating a product variant
+type VariantRequest struct {
+	SKU        string     `json:"sku" validate:"required,sku"`
+	Name       string     `json:"name" validate:"max=255"`
+	Price      float64    `json:"price" validate:"required,min=0"`
+	Attributes Attributes `json:"attributes" validate:"omitempty,max=50,dive,keys,attrkey,endkeys,attrvalue"`
+}
+
+// PriceRange is the lowest and highest price a product sells for across its variants
+type PriceRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// ProductIncludes selects the related data embedded in product responses
+type ProductIncludes struct {
+	Variants   bool
+	PriceRange bool
+	Images     bool
+}
+
+// Any reports whether any related data was requested
+func (pi ProductIncludes) Any() bool {
+	return pi.Variants || pi.PriceRange || pi.Images
+}
+
+// ToVariant converts VariantRequest to a ProductVariant of the product
+func (vr *VariantRequest) ToVariant(productID uuid.UUID) ProductVariant {
+	now := time.Now()
+	return ProductVariant{
+		ID:         uuid.New(),
+		ProductID:  productID,
+		SKU:        vr.SKU,
+		Name:       vr.Name,
+		Price:      vr.Price,
+		Attributes: vr.attributes(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// ApplyTo copies the editable fields of the request onto an existing variant
+func (vr *VariantRequest) ApplyTo(v *ProductVariant) {
+	v.SKU = vr.SKU
+	v.Name = vr.Name
+	v.Price = vr.Price
+	v.Attributes = vr.attributes()
+}
+
+// attributes returns the requested attributes, never nil so variants always list an object
+func (vr *VariantRequest) attributes() Attributes {
+	if vr.Attributes == nil {
+		return Attributes{}
+	}
+	return vr.Attributes
+}
+
+// AttachVariants embeds the requested variant data into products. Variants may belong to
+// any of the products; a product without variants has a price range of its own price.
+func AttachVariants(products []Product, variants []ProductVariant, include ProductIncludes) {
+	byProduct := make(map[uuid.UUID][]ProductVariant, len(products))
+	for _, variant := range variants {
+		byProduct[variant.ProductID] = append(byProduct[variant.ProductID], variant)
+	}
+
+	for i := range products {
+		own := byProduct[products[i].ID]
+		if include.Variants {
+			products[i].Variants = own
+			if products[i].Variants == nil {
+				products[i].Variants = []ProductVariant{}
+			}
+		}
+		if include.PriceRange {
+			priceRange := PriceRange{Min: products[i].Price, Max: products[i].Price}
+			for j, variant := range own {
+				if j == 0 || variant.Price < priceRange.Min {
+					priceRange.Min = variant.Price
+				}
+				if j == 0 || variant.Price > priceRange.Max {
+					priceRange.Max = variant.Price
+				}
+			}
+			products[i].PriceRange = &priceRange
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// APIKeyFileRepository keeps API keys in a local JSON file, for development without PostgreSQL.
+// The whole file is rewritten on every change.
+type APIKeyFileRepository struct {
+	path  string
+	mutex sync.RWMutex
+	keys  []models.APIKey
+}
+
+// apiKeyFileRecord is the stored form of a key; unlike the API representation it keeps the hash
+type apiKeyFileRecord struct {
+	models.APIKey
+	Hash string `json:"key_hash"`
+}
+
+// NewAPIKeyFileRepository loads the keys stored at path; a missing file holds no keys
+func NewAPIKeyFileRepository(path string) (*APIKeyFileRepository, error) {
+	r := &APIKeyFileRepository{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []apiKeyFileRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		key := record.APIKey
+		key.Hash = record.Hash
+		r.keys = append(r.keys, key)
+	}
+	return r, nil
+}
+
+// CreateAPIKey stores a new API key
+func (r *APIKeyFileRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.keys = append(r.keys, *key)
+	if err := r.save(); err != nil {
+		r.keys = r.keys[:len(r.keys)-1]
+		return err
+	}
+	return nil
+}
+
+// ListAPIKeys returns all API keys, newest first
+func (r *APIKeyFileRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]models.APIKey, len(r.keys))
+	copy(keys, r.keys)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// RevokeAPIKey marks an API key as revoked
+func (r *APIKeyFileRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID != id {
+			continue
+		}
+		if r.keys[i].RevokedAt == nil {
+			now := time.Now()
+			r.keys[i].RevokedAt = &now
+			if err := r.save(); err != nil {
+				r.keys[i].RevokedAt = nil
+				return nil, err
+			}
+		}
+		key := r.keys[i]
+		return &key, nil
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+// FindAPIKeyByHash returns the unrevoked API key with the given hash
+func (r *APIKeyFileRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for i := range r.keys {
+		if r.keys[i].Hash == hash && r.keys[i].RevokedAt == nil {
+			key := r.keys[i]
+			return &key, nil
+		}
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+// TouchAPIKey records the last use of an API key; an earlier time never overwrites a later one
+func (r *APIKeyFileRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID != id {
+			continue
+		}
+		if r.keys[i].LastUsedAt != nil && !r.keys[i].LastUsedAt.Before(usedAt) {
+			return nil
+		}
+		previous := r.keys[i].LastUsedAt
+		r.keys[i].LastUsedAt = &usedAt
+		if err := r.save(); err != nil {
+			r.keys[i].LastUsedAt = previous
+			return err
+		}
+		return nil
+	}
+	return ErrAPIKeyNotFound
+}
+
+// save writes the keys to a temporary file readable only by the owner and renames it over the
+// previous file, so that a crash never leaves a partial file; callers must hold the write lock
+func (r *APIKeyFileRepository) save() error {
+	records := make([]apiKeyFileRecord, len(r.keys))
+	for i, key := range r.keys {
+		records[i] = apiKeyFileRecord{APIKey: key, Hash: key.Hash}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), r.path)
+}
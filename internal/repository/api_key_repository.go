@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+)
+
+// APIKeyStore persists API keys: PostgreSQL in deployments, a local file in development
+type APIKeyStore interface {
+	// CreateAPIKey stores a new key
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+
+	// ListAPIKeys returns every key, revoked ones included, newest first
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+
+	// RevokeAPIKey revokes a key and returns it; revoking a revoked key keeps its revocation time
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+
+	// FindAPIKeyByHash returns the unrevoked key with the given hash
+	FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+
+	// TouchAPIKey records when a key was last used
+	TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+// APIKeyRepository stores API keys in PostgreSQL
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// CreateAPIKey inserts a new API key
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys 
+		(id, name, prefix, key_hash, scopes, rate_limit, created_by, created_at) 
+		VALUES (:id, :name, :prefix, :key_hash, :scopes, :rate_limit, :created_by, :created_at)
+	`
+	_, err := r.db.NamedExecContext(ctx, query, key)
+	return err
+}
+
+// ListAPIKeys retrieves all API keys, newest first
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	query := `SELECT * FROM api_keys ORDER BY created_at DESC, id DESC`
+
+	err := r.db.SelectContext(ctx, &keys, query)
+	return keys, err
+}
+
+// RevokeAPIKey marks an API key as revoked
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `
+		UPDATE api_keys 
+		SET revoked_at = COALESCE(revoked_at, $2) 
+		WHERE id = $1 
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, &key, query, id, time.Now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindAPIKeyByHash retrieves the unrevoked API key with the given hash
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `SELECT * FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`
+
+	err := r.db.GetContext(ctx, &key, query, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchAPIKey records the last use of an API key; an earlier time never overwrites a later one
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := `
+		UPDATE api_keys 
+		SET last_used_at = $2 
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)
+	`
+	_, err := r.db.ExecContext(ctx, query, id, usedAt)
+	return err
+}
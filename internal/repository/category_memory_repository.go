@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// CreateCategory adds a new category below its parent, if any
+func (r *ProductMemoryRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if category.ParentID != nil {
+		if err := r.checkCategories(models.UUIDArray{*category.ParentID}); err != nil {
+			return err
+		}
+	}
+
+	r.categories[category.ID] = *category
+	return nil
+}
+
+// GetCategory retrieves a category by its UUID
+func (r *ProductMemoryRepository) GetCategory(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	return &category, nil
+}
+
+// ListCategories retrieves all categories ordered by name
+func (r *ProductMemoryRepository) ListCategories(ctx context.Context) ([]models.Category, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	categories := make([]models.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID.String() < categories[j].ID.String()
+	})
+	return categories, nil
+}
+
+// UpdateCategory modifies a category, possibly moving it to another parent
+func (r *ProductMemoryRepository) UpdateCategory(ctx context.Context, id uuid.UUID, req *models.CategoryRequest) (*models.Category, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+
+	if req.ParentID != nil {
+		if err := r.checkCategories(models.UUIDArray{*req.ParentID}); err != nil {
+			return nil, err
+		}
+
+		// The new parent must not sit inside the subtree being moved
+		if r.categorySubtree(id)[*req.ParentID] {
+			return nil, ErrCategoryCycle
+		}
+	}
+
+	req.ApplyTo(&category)
+	category.UpdatedAt = time.Now()
+	r.categories[id] = category
+	return &category, nil
+}
+
+// DeleteCategory removes a category that has no subcategories and no products
+func (r *ProductMemoryRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return ErrCategoryNotFound
+	}
+
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			return ErrCategoryInUse
+		}
+	}
+
+	// Tombstones count too, so restoring a product cannot resurrect a dangling reference
+	for i := range r.products {
+		if r.products[i].CategoryIDs.Contains(id) {
+			return ErrCategoryInUse
+		}
+	}
+
+	delete(r.categories, id)
+	return nil
+}
+
+// checkCategories verifies that every referenced category exists; callers must hold the lock
+func (r *ProductMemoryRepository) checkCategories(ids models.UUIDArray) error {
+	for _, id := range ids {
+		if _, ok := r.categories[id]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownCategory, id)
+		}
+	}
+	return nil
+}
+
+// checkProduct verifies the categories a product references and validates its attributes
+// against their schemas; callers must hold the lock
+func (r *ProductMemoryRepository) checkProduct(product *models.Product) error {
+	if err := r.checkCategories(product.CategoryIDs); err != nil {
+		return err
+	}
+	return r.checkAttributes(product)
+}
+
+// checkAttributes validates product attributes against the schemas of its categories and
+// their ancestors; callers must hold the lock
+func (r *ProductMemoryRepository) checkAttributes(product *models.Product) error {
+	var schemas []models.AttributeSchema
+	visited := make(map[uuid.UUID]bool)
+	for _, id := range product.CategoryIDs {
+		for current, ok := r.categories[id]; ok && !visited[current.ID]; {
+			visited[current.ID] = true
+			if len(current.AttributeSchema) > 0 {
+				schemas = append(schemas, current.AttributeSchema)
+			}
+			if current.ParentID == nil {
+				break
+			}
+			current, ok = r.categories[*current.ParentID]
+		}
+	}
+
+	if err := models.ValidateAttributes(product.Attributes, schemas); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAttributes, err)
+	}
+	return nil
+}
+
+// categorySubtree returns the IDs of a category and all of its descendants; callers must hold the lock
+func (r *ProductMemoryRepository) categorySubtree(id uuid.UUID) map[uuid.UUID]bool {
+	children := make(map[uuid.UUID][]uuid.UUID, len(r.categories))
+	for _, category := range r.categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	subtree := map[uuid.UUID]bool{id: true}
+	pending := []uuid.UUID{id}
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, child := range children[current] {
+			if !subtree[child] {
+				subtree[child] = true
+				pending = append(pending, child)
+			}
+		}
+	}
+	return subtree
+}
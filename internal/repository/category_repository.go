@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+)
+
+// categoryTreeLockKey is the advisory lock that serialises changes to the category tree,
+// so concurrent moves cannot create a cycle
+const categoryTreeLockKey = 7358100351
+
+// categorySubtree returns a query selecting the category bound to param and all of its descendants
+func categorySubtree(param string) string {
+	return fmt.Sprintf(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = %s
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT id FROM subtree
+	`, param)
+}
+
+// CreateCategory inserts a new category below its parent, if any
+func (r *ProductRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := r.lockCategoryTree(ctx, tx); err != nil {
+			return err
+		}
+		if category.ParentID != nil {
+			if err := r.checkCategories(ctx, tx, models.UUIDArray{*category.ParentID}); err != nil {
+				return err
+			}
+		}
+
+		query := `
+			INSERT INTO categories 
+			(id, name, description, parent_id, attribute_schema, created_at, updated_at) 
+			VALUES (:id, :name, :description, :parent_id, :attribute_schema, :created_at, :updated_at)
+		`
+		_, err := tx.NamedExecContext(ctx, query, category)
+		return err
+	})
+}
+
+// GetCategory retrieves a category by its UUID
+func (r *ProductRepository) GetCategory(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	query := `SELECT * FROM categories WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &category, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListCategories retrieves all categories ordered by name
+func (r *ProductRepository) ListCategories(ctx context.Context) ([]models.Category, error) {
+	categories := []models.Category{}
+	query := `SELECT * FROM categories ORDER BY name ASC, id ASC`
+
+	if err := r.db.SelectContext(ctx, &categories, query); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// UpdateCategory modifies a category, possibly moving it to another parent
+func (r *ProductRepository) UpdateCategory(ctx context.Context, id uuid.UUID, req *models.CategoryRequest) (*models.Category, error) {
+	var category models.Category
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := r.lockCategoryTree(ctx, tx); err != nil {
+			return err
+		}
+
+		query := `SELECT * FROM categories WHERE id = $1 FOR UPDATE`
+		err := tx.GetContext(ctx, &category, query, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if req.ParentID != nil {
+			if err := r.checkCategories(ctx, tx, models.UUIDArray{*req.ParentID}); err != nil {
+				return err
+			}
+
+			// The new parent must not sit inside the subtree being moved
+			var subtree []uuid.UUID
+			if err := tx.SelectContext(ctx, &subtree, categorySubtree("$1"), id); err != nil {
+				return err
+			}
+			if models.UUIDArray(subtree).Contains(*req.ParentID) {
+				return ErrCategoryCycle
+			}
+		}
+
+		req.ApplyTo(&category)
+		category.UpdatedAt = time.Now()
+
+		query = `
+			UPDATE categories 
+			SET name = :name, 
+				description = :description, 
+				parent_id = :parent_id, 
+				attribute_schema = :attribute_schema, 
+				updated_at = :updated_at 
+			WHERE id = :id
+		`
+		_, err = tx.NamedExecContext(ctx, query, category)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// DeleteCategory removes a category that has no subcategories and no products
+func (r *ProductRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := r.lockCategoryTree(ctx, tx); err != nil {
+			return err
+		}
+
+		// Locking the row waits for product writes that are assigning it
+		var locked uuid.UUID
+		err := tx.GetContext(ctx, &locked, `SELECT id FROM categories WHERE id = $1 FOR UPDATE`, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Tombstones count too, so restoring a product cannot resurrect a dangling reference
+		var inUse bool
+		query := `
+			SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)
+				OR EXISTS (SELECT 1 FROM products WHERE category_ids @> ARRAY[$1::uuid])
+		`
+		if err := tx.GetContext(ctx, &inUse, query, id); err != nil {
+			return err
+		}
+		if inUse {
+			return ErrCategoryInUse
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+		return err
+	})
+}
+
+// checkCategories verifies that every referenced category exists and keeps them from being
+// deleted until the transaction ends
+func (r *ProductRepository) checkCategories(ctx context.Context, tx *sqlx.Tx, ids models.UUIDArray) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var found []uuid.UUID
+	query := `SELECT id FROM categories WHERE id = ANY($1::uuid[]) FOR SHARE`
+	if err := tx.SelectContext(ctx, &found, query, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !models.UUIDArray(found).Contains(id) {
+			return fmt.Errorf("%w: %s", ErrUnknownCategory, id)
+		}
+	}
+	return nil
+}
+
+// checkAttributes validates product attributes against the schemas of its categories and
+// their ancestors. Callers check the categories exist first.
+func (r *ProductRepository) checkAttributes(ctx context.Context, tx *sqlx.Tx, product *models.Product) error {
+	if len(product.CategoryIDs) == 0 {
+		return nil
+	}
+
+	var schemas []models.AttributeSchema
+	query := `
+		WITH RECURSIVE lineage AS (
+			SELECT id, parent_id, attribute_schema FROM categories WHERE id = ANY($1::uuid[])
+			UNION
+			SELECT c.id, c.parent_id, c.attribute_schema FROM categories c JOIN lineage l ON c.id = l.parent_id
+		)
+		SELECT attribute_schema FROM lineage WHERE attribute_schema <> '{}'::jsonb
+	`
+	if err := tx.SelectContext(ctx, &schemas, query, product.CategoryIDs); err != nil {
+		return err
+	}
+	if err := models.ValidateAttributes(product.Attributes, schemas); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAttributes, err)
+	}
+	return nil
+}
+
+// lockCategoryTree serialises structural changes to the category tree until the transaction ends
+func (r *ProductRepository) lockCategoryTree(ctx context.Context, tx *sqlx.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLockKey)
+	return err
+}
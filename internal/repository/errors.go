@@ -0,0 +1,89 @@
+package repository
+
+import "errors"
+
+var (
+	// ErrProductNotFound is returned when no product matches the given ID
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrVersionMismatch is returned when a conditional write targets a stale version
+	ErrVersionMismatch = errors.New("product version mismatch")
+
+	// ErrProductNotDeleted is returned when restoring a product that has no tombstone
+	ErrProductNotDeleted = errors.New("product is not deleted")
+
+	// ErrProductExists is returned when a create-only write targets an existing product
+	ErrProductExists = errors.New("product already exists")
+
+	// ErrProductDeleted is returned when a write targets the ID of a soft-deleted product
+	ErrProductDeleted = errors.New("product is deleted")
+
+	// ErrInvalidOperation is returned for a batch operation of an unknown kind or missing the fields its kind needs
+	ErrInvalidOperation = errors.New("invalid batch operation")
+
+	// ErrInvalidProduct is returned when a batch operation would leave a product that fails validation
+	ErrInvalidProduct = errors.New("invalid product")
+
+	// ErrInvalidPatch is returned when a batch operation carries a patch document that cannot be applied
+	ErrInvalidPatch = errors.New("invalid patch")
+
+	// ErrBatchAborted is returned for the operations of an atomic batch that was rolled back because another failed
+	ErrBatchAborted = errors.New("not applied: another operation of the batch failed")
+
+	// ErrInvalidTransition is returned when a product cannot move from its lifecycle state to the requested one
+	ErrInvalidTransition = errors.New("invalid status transition")
+
+	// ErrDuplicateSKU is returned when a write would give two live products the same SKU
+	ErrDuplicateSKU = errors.New("product sku already exists")
+
+	// ErrVariantNotFound is returned when no variant of the product matches the given ID
+	ErrVariantNotFound = errors.New("product variant not found")
+
+	// ErrDuplicateVariantSKU is returned when a write would give two variants the same SKU
+	ErrDuplicateVariantSKU = errors.New("variant sku already exists")
+
+	// ErrImageNotFound is returned when no image of the product matches the given ID
+	ErrImageNotFound = errors.New("product image not found")
+
+	// ErrInvalidImageOrder is returned when a new image order does not list every image of the product once
+	ErrInvalidImageOrder = errors.New("invalid image order")
+
+	// ErrTranslationNotFound is returned when a product has no translation in the given locale
+	ErrTranslationNotFound = errors.New("product translation not found")
+
+	// ErrInsufficientStock is returned when a stock movement would take more than is available
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrInsufficientReserved is returned when releasing more stock than is reserved
+	ErrInsufficientReserved = errors.New("release exceeds reserved stock")
+
+	// ErrCategoryNotFound is returned when no category matches the given ID
+	ErrCategoryNotFound = errors.New("category not found")
+
+	// ErrUnknownCategory is returned when a write references a category that does not exist
+	ErrUnknownCategory = errors.New("referenced category does not exist")
+
+	// ErrInvalidAttributes is returned when product attributes break the schema of one of its categories
+	ErrInvalidAttributes = errors.New("attributes do not match the category schema")
+
+	// ErrCategoryCycle is returned when moving a category below itself or one of its descendants
+	ErrCategoryCycle = errors.New("category cannot be its own ancestor")
+
+	// ErrCategoryInUse is returned when deleting a category that still has subcategories or products
+	ErrCategoryInUse = errors.New("category has subcategories or products")
+
+	// ErrHistoryNotFound is returned when no audit entry exists for a product version
+	ErrHistoryNotFound = errors.New("product history not found")
+
+	// ErrScheduleNotFound is returned when no price schedule matches the given ID
+	ErrScheduleNotFound = errors.New("price schedule not found")
+
+	// ErrScheduleOverlap is returned when a price schedule overlaps another open schedule
+	ErrScheduleOverlap = errors.New("price schedule overlaps an existing schedule")
+
+	// ErrScheduleClosed is returned when cancelling a schedule that already finished
+	ErrScheduleClosed = errors.New("price schedule is no longer open")
+
+	// ErrAPIKeyNotFound is returned when no API key matches the given ID, or no active key the given hash
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
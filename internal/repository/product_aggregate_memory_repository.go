@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// Aggregate computes counts and statistics over the products matching the filter in a
+// single pass over the store
+func (r *ProductMemoryRepository) Aggregate(ctx context.Context, filter models.ProductFilter, options models.AggregateOptions) (*models.ProductAggregate, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	matches := r.productMatcher(filter)
+	var prices []float64
+	created := make(map[time.Time]int)
+	groups := make(map[string]map[string]int, len(options.GroupBy))
+	for _, dimension := range options.GroupBy {
+		groups[dimension] = make(map[string]int)
+	}
+
+	var sum float64
+	for i := range r.products {
+		product := &r.products[i]
+		if !matches(product) {
+			continue
+		}
+		prices = append(prices, product.Price)
+		sum += product.Price
+		created[models.TruncateTime(product.CreatedAt, options.CreatedInterval)]++
+
+		for dimension, counts := range groups {
+			switch dimension {
+			case models.GroupByStatus:
+				counts[product.Status]++
+			case models.GroupByCategory:
+				for _, categoryID := range product.CategoryIDs {
+					counts[categoryID.String()]++
+				}
+			case models.GroupByNamePrefix:
+				counts[namePrefix(product.Name, options.NamePrefixLength)]++
+			}
+		}
+	}
+
+	aggregate := &models.ProductAggregate{
+		Count:            len(prices),
+		PriceHistogram:   []models.PriceBucket{},
+		CreatedHistogram: []models.TimeBucket{},
+	}
+
+	if len(prices) > 0 {
+		sort.Float64s(prices)
+		min, max := prices[0], prices[len(prices)-1]
+		aggregate.Price = &models.PriceStats{
+			Min:         min,
+			Max:         max,
+			Avg:         sum / float64(len(prices)),
+			Percentiles: make(map[string]float64, len(options.Percentiles)),
+		}
+		for _, percentile := range options.Percentiles {
+			aggregate.Price.Percentiles[models.PercentileKey(percentile)] = models.Percentile(prices, percentile)
+		}
+
+		aggregate.PriceHistogram = models.NewPriceHistogram(min, max, options.PriceBuckets)
+		for _, price := range prices {
+			aggregate.PriceHistogram[models.PriceBucketIndex(price, min, max, len(aggregate.PriceHistogram))].Count++
+		}
+	}
+
+	for start, count := range created {
+		aggregate.CreatedHistogram = append(aggregate.CreatedHistogram, models.TimeBucket{Start: start, Count: count})
+	}
+	sort.Slice(aggregate.CreatedHistogram, func(i, j int) bool {
+		return aggregate.CreatedHistogram[i].Start.Before(aggregate.CreatedHistogram[j].Start)
+	})
+
+	if len(groups) > 0 {
+		aggregate.Groups = make(map[string][]models.GroupCount, len(groups))
+		for dimension, counts := range groups {
+			result := make([]models.GroupCount, 0, len(counts))
+			for key, count := range counts {
+				result = append(result, models.GroupCount{Key: key, Count: count})
+			}
+			models.SortGroupCounts(result)
+			aggregate.Groups[dimension] = result
+		}
+	}
+	return aggregate, nil
+}
+
+// namePrefix returns the first length characters of a name in upper case
+func namePrefix(name string, length int) string {
+	runes := []rune(name)
+	if len(runes) > length {
+		runes = runes[:length]
+	}
+	return strings.ToUpper(string(runes))
+}
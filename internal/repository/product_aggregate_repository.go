@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"product-service/internal/models"
+)
+
+// Aggregate computes counts and statistics over the products matching the filter. The
+// queries share one snapshot, so the figures agree with each other.
+func (r *ProductRepository) Aggregate(ctx context.Context, filter models.ProductFilter, options models.AggregateOptions) (*models.ProductAggregate, error) {
+	where, args := buildProductFilter(filter)
+	aggregate := &models.ProductAggregate{
+		PriceHistogram:   []models.PriceBucket{},
+		CreatedHistogram: []models.TimeBucket{},
+	}
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+			return err
+		}
+
+		fractions := make(pq.Float64Array, len(options.Percentiles))
+		for i, percentile := range options.Percentiles {
+			fractions[i] = percentile / 100
+		}
+
+		var stats struct {
+			Count       int             `db:"count"`
+			Min         float64         `db:"min"`
+			Max         float64         `db:"max"`
+			Avg         float64         `db:"avg"`
+			Percentiles pq.Float64Array `db:"percentiles"`
+		}
+		query := fmt.Sprintf(`
+			SELECT COUNT(*) AS count,
+				COALESCE(MIN(price), 0) AS min,
+				COALESCE(MAX(price), 0) AS max,
+				COALESCE(AVG(price), 0) AS avg,
+				percentile_cont($%d::float8[]) WITHIN GROUP (ORDER BY price::float8) AS percentiles
+			FROM products %s
+		`, len(args)+1, where)
+		if err := tx.GetContext(ctx, &stats, query, append(args, fractions)...); err != nil {
+			return err
+		}
+
+		aggregate.Count = stats.Count
+		if stats.Count == 0 {
+			return nil
+		}
+
+		aggregate.Price = &models.PriceStats{
+			Min:         stats.Min,
+			Max:         stats.Max,
+			Avg:         stats.Avg,
+			Percentiles: make(map[string]float64, len(options.Percentiles)),
+		}
+		for i, percentile := range options.Percentiles {
+			aggregate.Price.Percentiles[models.PercentileKey(percentile)] = stats.Percentiles[i]
+		}
+
+		// width_bucket puts the highest price in a bucket of its own, so it is folded into the last one
+		aggregate.PriceHistogram = models.NewPriceHistogram(stats.Min, stats.Max, options.PriceBuckets)
+		if buckets := len(aggregate.PriceHistogram); buckets == 1 {
+			aggregate.PriceHistogram[0].Count = stats.Count
+		} else {
+			var counts []struct {
+				Bucket int `db:"bucket"`
+				Count  int `db:"count"`
+			}
+			n := len(args)
+			query = fmt.Sprintf(`
+				SELECT LEAST(width_bucket(price, $%d::numeric, $%d::numeric, $%d::int), $%d::int) - 1 AS bucket,
+					COUNT(*) AS count
+				FROM products %s
+				GROUP BY 1
+			`, n+1, n+2, n+3, n+3, where)
+			if err := tx.SelectContext(ctx, &counts, query, append(args, stats.Min, stats.Max, buckets)...); err != nil {
+				return err
+			}
+			for _, count := range counts {
+				aggregate.PriceHistogram[count.Bucket].Count = count.Count
+			}
+		}
+
+		query = fmt.Sprintf(`
+			SELECT date_trunc($%d, created_at AT TIME ZONE 'UTC') AS start, COUNT(*) AS count
+			FROM products %s
+			GROUP BY 1
+			ORDER BY 1
+		`, len(args)+1, where)
+		if err := tx.SelectContext(ctx, &aggregate.CreatedHistogram, query, append(args, options.CreatedInterval)...); err != nil {
+			return err
+		}
+		for i := range aggregate.CreatedHistogram {
+			aggregate.CreatedHistogram[i].Start = aggregate.CreatedHistogram[i].Start.UTC()
+		}
+
+		if len(options.GroupBy) > 0 {
+			aggregate.Groups = make(map[string][]models.GroupCount, len(options.GroupBy))
+		}
+		for _, dimension := range options.GroupBy {
+			groupArgs := args
+			switch dimension {
+			case models.GroupByStatus:
+				query = fmt.Sprintf(`SELECT status AS key, COUNT(*) AS count FROM products %s GROUP BY 1`, where)
+			case models.GroupByCategory:
+				query = fmt.Sprintf(`
+					SELECT category_id::text AS key, COUNT(*) AS count
+					FROM products CROSS JOIN LATERAL unnest(category_ids) AS category_id
+					%s
+					GROUP BY 1
+				`, where)
+			case models.GroupByNamePrefix:
+				groupArgs = append(args, options.NamePrefixLength)
+				query = fmt.Sprintf(`SELECT upper(left(name, $%d)) AS key, COUNT(*) AS count FROM products %s GROUP BY 1`,
+					len(groupArgs), where)
+			}
+
+			groups := []models.GroupCount{}
+			if err := tx.SelectContext(ctx, &groups, query, groupArgs...); err != nil {
+				return err
+			}
+			models.SortGroupCounts(groups)
+			aggregate.Groups[dimension] = groups
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aggregate, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// Batch runs a batch of product writes under one lock and reports the outcome of each
+// operation. An atomic batch stages every operation before storing any, so a failure leaves
+// the store untouched; otherwise each operation is stored as soon as it succeeds. validate
+// checks product requests, including the result of each patch. It reports whether the
+// batch was committed.
+func (r *ProductMemoryRepository) Batch(ctx context.Context, ops []models.BatchOperation, atomic bool, validate func(interface{}) error) ([]models.BatchResult, bool, error) {
+	results, ok := checkBatch(ops, validate)
+	if !ok && atomic {
+		abortBatch(results)
+		return results, false, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	batch := &memoryBatch{repo: r, staged: make(map[uuid.UUID]models.Product)}
+	for i := range ops {
+		if results[i].Err != nil {
+			continue
+		}
+
+		product, err := batch.stage(&ops[i], validate)
+		if err != nil {
+			results[i].Err = err
+			if atomic {
+				abortBatch(results)
+				return results, false, nil
+			}
+			continue
+		}
+		completeBatchResult(&results[i], product)
+
+		if !atomic {
+			batch.commit(ctx)
+		}
+	}
+	batch.commit(ctx)
+	return results, true, nil
+}
+
+// memoryBatch stages the writes of a batch on top of the stored products; callers must hold
+// the write lock
+type memoryBatch struct {
+	repo   *ProductMemoryRepository
+	staged map[uuid.UUID]models.Product
+	writes []stagedWrite
+}
+
+// stagedWrite is a product write waiting to be stored
+type stagedWrite struct {
+	action  string
+	product models.Product
+}
+
+// product returns the staged or stored state of a product, tombstones included
+func (b *memoryBatch) product(id uuid.UUID) (models.Product, bool) {
+	if product, ok := b.staged[id]; ok {
+		return product, true
+	}
+	if index := b.repo.findIndex(id, true); index >= 0 {
+		return b.repo.products[index], true
+	}
+	return models.Product{}, false
+}
+
+// liveProduct returns the state of a live product an operation applies to
+func (b *memoryBatch) liveProduct(op *models.BatchOperation) (models.Product, error) {
+	product, ok := b.product(op.ID)
+	if !ok || product.DeletedAt != nil {
+		return models.Product{}, ErrProductNotFound
+	}
+	if op.Version != 0 && product.Version != op.Version {
+		return models.Product{}, ErrVersionMismatch
+	}
+	return product, nil
+}
+
+// stage checks one operation against the staged state and queues its write
+func (b *memoryBatch) stage(op *models.BatchOperation, validate func(interface{}) error) (*models.Product, error) {
+	var (
+		product models.Product
+		action  string
+	)
+	now := time.Now()
+	switch op.Op {
+	case models.BatchOpCreate:
+		product = op.Product.ToProduct()
+		if op.ID != uuid.Nil {
+			product.ID = op.ID
+		}
+		if _, exists := b.product(product.ID); exists {
+			return nil, ErrProductExists
+		}
+		action = models.HistoryActionCreate
+
+	case models.BatchOpUpdate:
+		current, err := b.liveProduct(op)
+		if err != nil {
+			return nil, err
+		}
+		if len(op.Product.Changes(&current)) == 0 {
+			return &current, nil
+		}
+		product = current
+		op.Product.ApplyTo(&product)
+		action = models.HistoryActionUpdate
+
+	case models.BatchOpPatch:
+		current, err := b.liveProduct(op)
+		if err != nil {
+			return nil, err
+		}
+		req, err := patchedRequest(&current, op.Patch, validate)
+		if err != nil {
+			return nil, err
+		}
+		changes := req.Changes(&current)
+		if len(changes) == 0 {
+			return &current, nil
+		}
+		product = current
+		for column, value := range changes {
+			if err := applyProductChange(&product, column, value); err != nil {
+				return nil, err
+			}
+		}
+		action = models.HistoryActionPatch
+
+	default:
+		current, err := b.liveProduct(op)
+		if err != nil {
+			return nil, err
+		}
+		product = current
+		product.DeletedAt = &now
+		action = models.HistoryActionDelete
+	}
+
+	if action != models.HistoryActionCreate {
+		product.UpdatedAt = now
+		product.Version++
+	}
+	if product.DeletedAt == nil {
+		if err := b.checkSKU(&product); err != nil {
+			return nil, err
+		}
+		if err := b.repo.checkProduct(&product); err != nil {
+			return nil, err
+		}
+	}
+
+	b.staged[product.ID] = product
+	b.writes = append(b.writes, stagedWrite{action: action, product: product})
+	return &product, nil
+}
+
+// checkSKU verifies that no other live product, staged or stored, holds the product's SKU
+func (b *memoryBatch) checkSKU(product *models.Product) error {
+	if product.SKU == "" {
+		return nil
+	}
+	for id, staged := range b.staged {
+		if id != product.ID && staged.DeletedAt == nil && staged.SKU == product.SKU {
+			return fmt.Errorf("%w: %s", ErrDuplicateSKU, product.SKU)
+		}
+	}
+
+	// A stored holder that is staged was checked above with its staged SKU
+	if owner, ok := b.repo.skuIndex[product.SKU]; ok && owner != product.ID {
+		if _, staged := b.staged[owner]; !staged {
+			return fmt.Errorf("%w: %s", ErrDuplicateSKU, product.SKU)
+		}
+	}
+	return nil
+}
+
+// commit stores the staged writes in order
+func (b *memoryBatch) commit(ctx context.Context) {
+	for _, write := range b.writes {
+		b.repo.storeProduct(ctx, write.action, b.repo.findIndex(write.product.ID, true), write.product)
+	}
+	b.writes = nil
+	b.staged = make(map[uuid.UUID]models.Product)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// acceptAll stands in for the request validator
+func acceptAll(interface{}) error { return nil }
+
+// mixedBatch updates existing, creates a product and deletes a product that does not exist
+func mixedBatch(existing *models.Product) []models.BatchOperation {
+	return []models.BatchOperation{
+		{Op: models.BatchOpUpdate, ID: existing.ID, Version: existing.Version, Product: &models.ProductRequest{SKU: existing.SKU, Name: "Renamed", Price: 12}},
+		{Op: models.BatchOpCreate, Product: &models.ProductRequest{SKU: "BATCH-NEW", Name: "Created", Price: 5}},
+		{Op: models.BatchOpPatch, ID: existing.ID, Patch: json.RawMessage(`{"price":15}`)},
+		{Op: models.BatchOpDelete, ID: uuid.New()},
+	}
+}
+
+func TestMemoryAtomicBatchFailureLeavesStoreUnchanged(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	existing := createTestProduct(t, repo, "BATCH-1")
+
+	results, committed, err := repo.Batch(ctx, mixedBatch(existing), true, acceptAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if committed {
+		t.Fatal("a failing atomic batch was committed")
+	}
+	for i, want := range []error{ErrBatchAborted, ErrBatchAborted, ErrBatchAborted, ErrProductNotFound} {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("operation %d: got %v, want %v", i, results[i].Err, want)
+		}
+		if results[i].Product != nil {
+			t.Errorf("operation %d: aborted operation reports a product", i)
+		}
+	}
+
+	stored, err := repo.GetByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Version != existing.Version || stored.Name != existing.Name || stored.Price != existing.Price {
+		t.Fatalf("existing product changed to %+v", stored)
+	}
+	if _, err := repo.GetBySKU(ctx, "BATCH-NEW"); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("created product is visible: %v", err)
+	}
+	history, err := repo.GetHistory(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("got %d history entries, want only the create", len(history))
+	}
+}
+
+func TestMemoryAtomicBatchRejectsInvalidOperationsUpfront(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	existing := createTestProduct(t, repo, "BATCH-2")
+
+	ops := []models.BatchOperation{
+		{Op: models.BatchOpPatch, ID: existing.ID, Patch: json.RawMessage(`{"price":15}`)},
+		{Op: "increment", ID: existing.ID},
+	}
+	results, committed, err := repo.Batch(ctx, ops, true, acceptAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if committed || !errors.Is(results[0].Err, ErrBatchAborted) || !errors.Is(results[1].Err, ErrInvalidOperation) {
+		t.Fatalf("got committed=%v and results %+v", committed, results)
+	}
+	if stored, _ := repo.GetByID(ctx, existing.ID); stored.Version != existing.Version {
+		t.Fatalf("existing product changed to version %d", stored.Version)
+	}
+}
+
+func TestMemoryNonAtomicBatchReportsEachOperation(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	existing := createTestProduct(t, repo, "BATCH-3")
+
+	results, committed, err := repo.Batch(ctx, mixedBatch(existing), false, acceptAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !committed {
+		t.Fatal("a non-atomic batch was not committed")
+	}
+	for i := 0; i < 3; i++ {
+		if results[i].Err != nil {
+			t.Errorf("operation %d: unexpected error %v", i, results[i].Err)
+		}
+	}
+	if !errors.Is(results[3].Err, ErrProductNotFound) {
+		t.Errorf("operation 3: got %v, want ErrProductNotFound", results[3].Err)
+	}
+
+	// The patch applies on top of the update before it
+	stored, err := repo.GetByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != "Renamed" || stored.Price != 15 || stored.Version != existing.Version+2 {
+		t.Fatalf("got %+v after update and patch", stored)
+	}
+	if results[2].Product == nil || results[2].Product.Version != stored.Version {
+		t.Fatalf("patch result does not report the stored product: %+v", results[2].Product)
+	}
+
+	created, err := repo.GetBySKU(ctx, "BATCH-NEW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[1].ID == nil || *results[1].ID != created.ID {
+		t.Fatalf("create result reports id %v, stored %s", results[1].ID, created.ID)
+	}
+}
+
+func TestMemoryBatchChecksSKUsAgainstStagedWrites(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	existing := createTestProduct(t, repo, "BATCH-4")
+
+	// Moving a SKU from one product to another within a batch is allowed
+	ops := []models.BatchOperation{
+		{Op: models.BatchOpUpdate, ID: existing.ID, Product: &models.ProductRequest{SKU: "BATCH-5", Name: existing.Name, Price: existing.Price}},
+		{Op: models.BatchOpCreate, Product: &models.ProductRequest{SKU: "BATCH-4", Name: "Successor", Price: 1}},
+		{Op: models.BatchOpCreate, Product: &models.ProductRequest{SKU: "BATCH-5", Name: "Duplicate", Price: 1}},
+	}
+	results, committed, err := repo.Batch(ctx, ops, true, acceptAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if committed || !errors.Is(results[2].Err, ErrDuplicateSKU) {
+		t.Fatalf("got committed=%v and results %+v", committed, results)
+	}
+
+	results, committed, err = repo.Batch(ctx, ops[:2], true, acceptAll)
+	if err != nil || !committed {
+		t.Fatalf("got committed=%v, err=%v and results %+v", committed, err, results)
+	}
+	if successor, err := repo.GetBySKU(ctx, "BATCH-4"); err != nil || successor.Name != "Successor" {
+		t.Fatalf("got %+v, %v for the reused SKU", successor, err)
+	}
+}
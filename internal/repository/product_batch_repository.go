@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+	"product-service/pkg/jsonpatch"
+)
+
+// Batch runs a batch of product writes in one transaction and reports the outcome of each
+// operation. An atomic batch commits all of its operations or, when one fails, none of them;
+// otherwise each operation runs under a savepoint, so a failure only undoes that operation.
+// The content of images removed with deleted products is deleted once the transaction has
+// committed. validate checks product requests, including the result of each patch. It reports
+// whether the transaction was committed.
+func (r *ProductRepository) Batch(ctx context.Context, ops []models.BatchOperation, atomic bool, validate func(interface{}) error) ([]models.BatchResult, bool, error) {
+	results, ok := checkBatch(ops, validate)
+	if !ok && atomic {
+		abortBatch(results)
+		return results, false, nil
+	}
+
+	operationFailed := false
+	var images []models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		for i := range ops {
+			if results[i].Err != nil {
+				continue
+			}
+
+			if !atomic {
+				if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_operation`); err != nil {
+					return err
+				}
+			}
+
+			product, removed, err := r.runBatchOperation(ctx, tx, &ops[i], validate)
+			if err != nil {
+				results[i].Err = translateConstraintError(err)
+				if atomic {
+					operationFailed = true
+					return err
+				}
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_operation`); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !atomic {
+				if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_operation`); err != nil {
+					return err
+				}
+			}
+			completeBatchResult(&results[i], product)
+			images = append(images, removed...)
+		}
+		return nil
+	})
+	if operationFailed {
+		abortBatch(results)
+		return results, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	removeImageBlobs(ctx, r.blobs, images)
+	return results, true, nil
+}
+
+// runBatchOperation performs one operation of a batch inside its transaction. A delete also
+// returns the images it removed.
+func (r *ProductRepository) runBatchOperation(ctx context.Context, tx *sqlx.Tx, op *models.BatchOperation, validate func(interface{}) error) (*models.Product, []models.ProductImage, error) {
+	switch op.Op {
+	case models.BatchOpCreate:
+		product := op.Product.ToProduct()
+		if op.ID != uuid.Nil {
+			product.ID = op.ID
+		}
+		inserted, err := r.insertIfAbsent(ctx, tx, insertProductIfAbsentQuery, &product)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !inserted {
+			return nil, nil, ErrProductExists
+		}
+		return &product, nil, nil
+
+	case models.BatchOpUpdate:
+		product, err := r.updateProduct(ctx, tx, op.ID, op.Product, op.Version)
+		return product, nil, err
+
+	case models.BatchOpPatch:
+		before, err := r.lockProduct(ctx, tx, op.ID, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if op.Version != 0 && before.Version != op.Version {
+			return nil, nil, ErrVersionMismatch
+		}
+		req, err := patchedRequest(before, op.Patch, validate)
+		if err != nil {
+			return nil, nil, err
+		}
+		product, err := r.patchProduct(ctx, tx, before, req.Changes(before))
+		return product, nil, err
+
+	default:
+		return r.deleteProduct(ctx, tx, op.ID, op.Version)
+	}
+}
+
+// checkBatch prepares the results of a batch and records the operations whose shape is
+// invalid. It reports whether every operation is well-formed.
+func checkBatch(ops []models.BatchOperation, validate func(interface{}) error) ([]models.BatchResult, bool) {
+	results := make([]models.BatchResult, len(ops))
+	ok := true
+	for i := range ops {
+		results[i] = models.BatchResult{Index: i, Op: ops[i].Op}
+		if ops[i].Op != models.BatchOpCreate && ops[i].ID != uuid.Nil {
+			id := ops[i].ID
+			results[i].ID = &id
+		}
+		if err := checkBatchOperation(&ops[i], validate); err != nil {
+			results[i].Err = err
+			ok = false
+		}
+	}
+	return results, ok
+}
+
+// checkBatchOperation verifies that an operation has the fields its kind needs and that
+// the product it carries is valid
+func checkBatchOperation(op *models.BatchOperation, validate func(interface{}) error) error {
+	switch op.Op {
+	case models.BatchOpCreate, models.BatchOpUpdate, models.BatchOpPatch, models.BatchOpDelete:
+	default:
+		return fmt.Errorf("%w: unknown op %q; use create, update, patch or delete", ErrInvalidOperation, op.Op)
+	}
+
+	if op.Op != models.BatchOpCreate && op.ID == uuid.Nil {
+		return fmt.Errorf("%w: %s requires an id", ErrInvalidOperation, op.Op)
+	}
+	switch op.Op {
+	case models.BatchOpCreate, models.BatchOpUpdate:
+		if op.Product == nil {
+			return fmt.Errorf("%w: %s requires a product", ErrInvalidOperation, op.Op)
+		}
+		if err := validate(op.Product); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+	case models.BatchOpPatch:
+		if len(bytes.TrimSpace(op.Patch)) == 0 {
+			return fmt.Errorf("%w: patch requires a patch document", ErrInvalidOperation)
+		}
+	}
+	return nil
+}
+
+// patchedRequest applies a JSON merge patch object or a JSON Patch array to the current state
+// of a product and validates the result
+func patchedRequest(current *models.Product, patch json.RawMessage, validate func(interface{}) error) (*models.ProductRequest, error) {
+	document, err := json.Marshal(current.ToRequest())
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	if patch = bytes.TrimSpace(patch); patch[0] == '[' {
+		patched, err = jsonpatch.Apply(document, patch)
+	} else {
+		patched, err = jsonpatch.MergePatch(document, patch)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	// Reject patches that introduce fields the product does not have
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+
+	var req models.ProductRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	if err := validate(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+	}
+	return &req, nil
+}
+
+// completeBatchResult records the product an operation wrote; deletes report no product
+func completeBatchResult(result *models.BatchResult, product *models.Product) {
+	id := product.ID
+	result.ID = &id
+	if result.Op != models.BatchOpDelete {
+		result.Product = product
+	}
+}
+
+// abortBatch marks the operations of a rolled back atomic batch that did not fail themselves
+func abortBatch(results []models.BatchResult) {
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		results[i].Err = ErrBatchAborted
+		results[i].Product = nil
+		if results[i].Op == models.BatchOpCreate {
+			results[i].ID = nil
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// BulkUpdate applies an update to every live product matching the filter. Every product is
+// checked before any is stored, so an update that fails for one product changes none.
+// A dry run only reports what the update would change.
+func (r *ProductMemoryRepository) BulkUpdate(ctx context.Context, filter models.ProductFilter, update *models.BulkUpdateRequest, dryRun bool) (*models.BulkResult, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := newBulkResult(dryRun)
+	indexes := r.bulkIndexes(filter)
+	result.Matched = len(indexes)
+
+	now := time.Now()
+	updated := make(map[int]models.Product)
+	for _, i := range indexes {
+		after := r.products[i]
+		changed, err := update.Apply(&after)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		}
+		if !changed {
+			continue
+		}
+		after.UpdatedAt = now
+		after.Version++
+		result.Add(after.ID)
+		updated[i] = after
+	}
+
+	if !dryRun {
+		for _, i := range indexes {
+			if after, ok := updated[i]; ok {
+				r.storeProduct(ctx, models.HistoryActionPatch, i, after)
+			}
+		}
+	}
+	return result, nil
+}
+
+// BulkDelete soft-deletes every live product matching the filter, together with its images.
+// A dry run only reports which products would be deleted.
+func (r *ProductMemoryRepository) BulkDelete(ctx context.Context, filter models.ProductFilter, dryRun bool) (*models.BulkResult, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := newBulkResult(dryRun)
+	indexes := r.bulkIndexes(filter)
+	result.Matched = len(indexes)
+
+	now := time.Now()
+	for _, i := range indexes {
+		result.Add(r.products[i].ID)
+		if dryRun {
+			continue
+		}
+
+		deleted := r.products[i]
+		deleted.DeletedAt = &now
+		deleted.UpdatedAt = now
+		deleted.Version++
+
+		r.storeProduct(ctx, models.HistoryActionDelete, i, deleted)
+	}
+	return result, nil
+}
+
+// bulkIndexes returns the slots of the live products matching the filter, oldest first;
+// callers must hold the lock
+func (r *ProductMemoryRepository) bulkIndexes(filter models.ProductFilter) []int {
+	filter.IncludeDeleted = false
+	matches := r.productMatcher(filter)
+
+	var indexes []int
+	for i := range r.products {
+		if matches(&r.products[i]) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+)
+
+// BulkUpdate applies an update to every live product matching the filter in one transaction.
+// A dry run only reads the matching products and reports what the update would change.
+func (r *ProductRepository) BulkUpdate(ctx context.Context, filter models.ProductFilter, update *models.BulkUpdateRequest, dryRun bool) (*models.BulkResult, error) {
+	result := newBulkResult(dryRun)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		products, err := r.selectBulkProducts(ctx, tx, filter, dryRun)
+		if err != nil {
+			return err
+		}
+		result.Matched = len(products)
+
+		now := time.Now()
+		var entries []models.ProductHistory
+		var repriced []models.Product
+		for i := range products {
+			before := &products[i]
+			after := *before
+			changed, err := update.Apply(&after)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+			}
+			if !changed {
+				continue
+			}
+			after.UpdatedAt = now
+			after.Version++
+			result.Add(after.ID)
+
+			if dryRun {
+				continue
+			}
+			if _, err := tx.NamedExecContext(ctx, updateProductQuery, after); err != nil {
+				return err
+			}
+			entries = append(entries, newHistoryEntry(ctx, models.HistoryActionPatch, before, &after))
+			if after.Price != before.Price {
+				repriced = append(repriced, after)
+			}
+		}
+
+		if err := r.recordHistory(ctx, tx, entries...); err != nil {
+			return err
+		}
+		return r.recordBulkPriceChanges(ctx, tx, repriced, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// BulkDelete soft-deletes every live product matching the filter, together with its images, in
+// one transaction. A dry run only reports which products would be deleted.
+func (r *ProductRepository) BulkDelete(ctx context.Context, filter models.ProductFilter, dryRun bool) (*models.BulkResult, error) {
+	result := newBulkResult(dryRun)
+	var images []models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		products, err := r.selectBulkProducts(ctx, tx, filter, dryRun)
+		if err != nil {
+			return err
+		}
+		result.Matched = len(products)
+
+		ids := make([]uuid.UUID, len(products))
+		for i := range products {
+			ids[i] = products[i].ID
+			result.Add(products[i].ID)
+		}
+		if dryRun || len(products) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		query := `
+			UPDATE products
+			SET deleted_at = $2,
+				updated_at = $2,
+				version = version + 1
+			WHERE id = ANY($1::uuid[])
+		`
+		if _, err := tx.ExecContext(ctx, query, models.UUIDArray(ids), now); err != nil {
+			return err
+		}
+
+		entries := make([]models.ProductHistory, len(products))
+		for i := range products {
+			after := products[i]
+			after.DeletedAt = &now
+			after.UpdatedAt = now
+			after.Version++
+			entries[i] = newHistoryEntry(ctx, models.HistoryActionDelete, &products[i], &after)
+		}
+		if err := r.recordHistory(ctx, tx, entries...); err != nil {
+			return err
+		}
+
+		images, err = deleteProductImages(ctx, tx, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeImageBlobs(ctx, r.blobs, images)
+	return result, nil
+}
+
+// selectBulkProducts loads the live products matching the filter, oldest first. Outside a dry
+// run the rows stay locked until the transaction ends; a dry run runs read-only.
+func (r *ProductRepository) selectBulkProducts(ctx context.Context, tx *sqlx.Tx, filter models.ProductFilter, dryRun bool) ([]models.Product, error) {
+	lock := "FOR UPDATE"
+	if dryRun {
+		lock = ""
+		if _, err := tx.ExecContext(ctx, `SET TRANSACTION READ ONLY`); err != nil {
+			return nil, err
+		}
+	}
+
+	filter.IncludeDeleted = false
+	where, args := buildProductFilter(filter)
+	query := fmt.Sprintf(`SELECT * FROM products %s ORDER BY created_at ASC, id ASC %s`, where, lock)
+
+	var products []models.Product
+	if err := tx.SelectContext(ctx, &products, query, args...); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// recordBulkPriceChanges closes the open price points of the repriced products and opens new ones
+func (r *ProductRepository) recordBulkPriceChanges(ctx context.Context, tx *sqlx.Tx, products []models.Product, now time.Time) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(products))
+	points := make([]models.PricePoint, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+		points[i] = models.PricePoint{
+			ProductID:     products[i].ID,
+			Price:         products[i].Price,
+			EffectiveFrom: now,
+		}
+	}
+
+	query := `UPDATE product_prices SET effective_to = $2 WHERE product_id = ANY($1::uuid[]) AND effective_to IS NULL`
+	if _, err := tx.ExecContext(ctx, query, models.UUIDArray(ids), now); err != nil {
+		return err
+	}
+	return r.insertPricePoints(ctx, tx, points...)
+}
+
+// newBulkResult prepares the result of a bulk operation
+func newBulkResult(dryRun bool) *models.BulkResult {
+	return &models.BulkResult{DryRun: dryRun, SampleIDs: []uuid.UUID{}}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// recordHistory appends an audit entry; callers must hold the write lock
+func (r *ProductMemoryRepository) recordHistory(entry models.ProductHistory) {
+	r.historySeq++
+	entry.ID = r.historySeq
+	r.history[entry.ProductID] = append(r.history[entry.ProductID], entry)
+}
+
+// GetHistory retrieves the audit trail of a product, oldest change first
+func (r *ProductMemoryRepository) GetHistory(ctx context.Context, productID uuid.UUID) ([]models.ProductHistory, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := r.history[productID]
+	result := make([]models.ProductHistory, len(entries))
+	copy(result, entries)
+	return result, nil
+}
+
+// GetHistoryVersion retrieves the audit entry that produced the given product version
+func (r *ProductMemoryRepository) GetHistoryVersion(ctx context.Context, productID uuid.UUID, version int64) (*models.ProductHistory, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := r.history[productID]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Version == version {
+			entry := entries[i]
+			return &entry, nil
+		}
+	}
+	return nil, ErrHistoryNotFound
+}
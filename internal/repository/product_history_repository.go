@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+	"product-service/pkg/requestctx"
+)
+
+// historyBatchSize bounds the rows per multi-row history insert (8 parameters each)
+const historyBatchSize = 1000
+
+// newHistoryEntry builds an audit entry attributed to the actor and request stored in ctx
+func newHistoryEntry(ctx context.Context, action string, before, after *models.Product) models.ProductHistory {
+	entry := models.ProductHistory{
+		Action:    action,
+		Actor:     requestctx.Actor(ctx),
+		RequestID: requestctx.RequestID(ctx),
+		Before:    models.NewSnapshot(before),
+		After:     models.NewSnapshot(after),
+		CreatedAt: time.Now(),
+	}
+
+	// The entry is keyed by the version the change produced
+	if after != nil {
+		entry.ProductID = after.ID
+		entry.Version = after.Version
+	} else if before != nil {
+		entry.ProductID = before.ID
+		entry.Version = before.Version
+	}
+	return entry
+}
+
+// recordHistory writes audit entries as part of the caller's transaction
+func (r *ProductRepository) recordHistory(ctx context.Context, tx *sqlx.Tx, entries ...models.ProductHistory) error {
+	query := `
+		INSERT INTO product_history 
+		(product_id, version, action, actor, request_id, before, after, created_at) 
+		VALUES (:product_id, :version, :action, :actor, :request_id, :before, :after, :created_at)
+	`
+
+	for start := 0; start < len(entries); start += historyBatchSize {
+		end := start + historyBatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		if _, err := tx.NamedExecContext(ctx, query, entries[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistory retrieves the audit trail of a product, oldest change first
+func (r *ProductRepository) GetHistory(ctx context.Context, productID uuid.UUID) ([]models.ProductHistory, error) {
+	var entries []models.ProductHistory
+	query := `SELECT * FROM product_history WHERE product_id = $1 ORDER BY version ASC, id ASC`
+
+	err := r.db.SelectContext(ctx, &entries, query, productID)
+	return entries, err
+}
+
+// GetHistoryVersion retrieves the audit entry that produced the given product version
+func (r *ProductRepository) GetHistoryVersion(ctx context.Context, productID uuid.UUID, version int64) (*models.ProductHistory, error) {
+	var entry models.ProductHistory
+	query := `SELECT * FROM product_history WHERE product_id = $1 AND version = $2 ORDER BY id DESC LIMIT 1`
+
+	err := r.db.GetContext(ctx, &entry, query, productID, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrHistoryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
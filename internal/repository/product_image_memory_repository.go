@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// AddImage stores the image content and its thumbnail and appends the image to the
+// product's images. The first image of a product becomes its primary image.
+func (r *ProductMemoryRepository) AddImage(ctx context.Context, image *models.ProductImage, content, thumbnail []byte) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(image.ProductID, false) < 0 {
+		return ErrProductNotFound
+	}
+	if err := storeImageBlobs(ctx, r.blobs, image, content, thumbnail); err != nil {
+		return err
+	}
+
+	images := r.images[image.ProductID]
+	image.Position = len(images)
+	image.Primary = len(images) == 0
+	r.images[image.ProductID] = append(images, *image)
+	return nil
+}
+
+// ListImages retrieves the images of a live product in display order
+func (r *ProductMemoryRepository) ListImages(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+
+	images := make([]models.ProductImage, len(r.images[productID]))
+	copy(images, r.images[productID])
+	return images, nil
+}
+
+// GetImage retrieves a single image of a live product
+func (r *ProductMemoryRepository) GetImage(ctx context.Context, productID, imageID uuid.UUID) (*models.ProductImage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	index := r.findImageIndex(productID, imageID)
+	if index < 0 {
+		return nil, ErrImageNotFound
+	}
+
+	image := r.images[productID][index]
+	return &image, nil
+}
+
+// OpenImage retrieves an image of a live product and opens its content, or the content of
+// its thumbnail; callers must close the returned reader
+func (r *ProductMemoryRepository) OpenImage(ctx context.Context, productID, imageID uuid.UUID, thumbnail bool) (*models.ProductImage, io.ReadCloser, error) {
+	image, err := r.GetImage(ctx, productID, imageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := openImageBlob(ctx, r.blobs, image, thumbnail)
+	if err != nil {
+		return nil, nil, err
+	}
+	return image, content, nil
+}
+
+// ReorderImages puts the images of a product in the given order. The IDs must list every
+// image of the product exactly once.
+func (r *ProductMemoryRepository) ReorderImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]models.ProductImage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+
+	current := r.images[productID]
+	if err := checkImageOrder(current, imageIDs); err != nil {
+		return nil, err
+	}
+
+	reordered := make([]models.ProductImage, len(imageIDs))
+	for position, imageID := range imageIDs {
+		image := current[r.findImageIndex(productID, imageID)]
+		image.Position = position
+		reordered[position] = image
+	}
+	r.images[productID] = reordered
+
+	result := make([]models.ProductImage, len(reordered))
+	copy(result, reordered)
+	return result, nil
+}
+
+// SetPrimaryImage makes the image the primary image of its product
+func (r *ProductMemoryRepository) SetPrimaryImage(ctx context.Context, productID, imageID uuid.UUID) (*models.ProductImage, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	index := r.findImageIndex(productID, imageID)
+	if index < 0 {
+		return nil, ErrImageNotFound
+	}
+
+	images := r.images[productID]
+	for i := range images {
+		images[i].Primary = i == index
+	}
+
+	image := images[index]
+	return &image, nil
+}
+
+// DeleteImage removes an image of a live product together with its content. The next image
+// in display order takes over as primary image.
+func (r *ProductMemoryRepository) DeleteImage(ctx context.Context, productID, imageID uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return ErrProductNotFound
+	}
+	index := r.findImageIndex(productID, imageID)
+	if index < 0 {
+		return ErrImageNotFound
+	}
+
+	images := r.images[productID]
+	removed := images[index]
+	images = append(images[:index], images[index+1:]...)
+	for i := range images {
+		images[i].Position = i
+	}
+	if removed.Primary && len(images) > 0 {
+		images[0].Primary = true
+	}
+	r.images[productID] = images
+
+	removeImageBlobs(ctx, r.blobs, []models.ProductImage{removed})
+	return nil
+}
+
+// IncludeRelated embeds the requested related data into the products
+func (r *ProductMemoryRepository) IncludeRelated(ctx context.Context, products []models.Product, include models.ProductIncludes) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.includeRelated(products, include)
+	return nil
+}
+
+// includeRelated embeds variants, price ranges and images as requested; callers must hold the lock
+func (r *ProductMemoryRepository) includeRelated(products []models.Product, include models.ProductIncludes) {
+	if include.Variants || include.PriceRange {
+		r.includeVariants(products, include)
+	}
+	if include.Images {
+		var images []models.ProductImage
+		for i := range products {
+			images = append(images, r.images[products[i].ID]...)
+		}
+		models.AttachImages(products, images)
+	}
+}
+
+// removeImages drops the images of a deleted or purged product and returns them so that their
+// content can be deleted; callers must hold the write lock
+func (r *ProductMemoryRepository) removeImages(productID uuid.UUID) []models.ProductImage {
+	images := r.images[productID]
+	delete(r.images, productID)
+	return images
+}
+
+// findImageIndex returns the slot of an image within its product, or -1; callers must hold the lock
+func (r *ProductMemoryRepository) findImageIndex(productID, imageID uuid.UUID) int {
+	for i := range r.images[productID] {
+		if r.images[productID][i].ID == imageID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"product-service/internal/models"
+	"product-service/pkg/logger"
+	"product-service/pkg/storage"
+)
+
+// AddImage stores the image content and its thumbnail and appends the image to the
+// product's images. The first image of a product becomes its primary image.
+// The content is written before the row, so a stored row always has its content.
+func (r *ProductRepository) AddImage(ctx context.Context, image *models.ProductImage, content, thumbnail []byte) error {
+	if err := storeImageBlobs(ctx, r.blobs, image, content, thumbnail); err != nil {
+		return err
+	}
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Locking the product serialises image writes, so positions and the primary flag stay consistent
+		if _, err := r.lockProduct(ctx, tx, image.ProductID, false); err != nil {
+			return err
+		}
+
+		var count int
+		query := `SELECT COUNT(*) FROM product_images WHERE product_id = $1`
+		if err := tx.GetContext(ctx, &count, query, image.ProductID); err != nil {
+			return err
+		}
+		image.Position = count
+		image.Primary = count == 0
+
+		query = `
+			INSERT INTO product_images
+			(id, product_id, filename, content_type, size, width, height, position, is_primary,
+			 blob_key, thumbnail_key, thumbnail_content_type, created_at)
+			VALUES (:id, :product_id, :filename, :content_type, :size, :width, :height, :position, :is_primary,
+			 :blob_key, :thumbnail_key, :thumbnail_content_type, :created_at)
+		`
+		_, err := tx.NamedExecContext(ctx, query, image)
+		return err
+	})
+	if err != nil {
+		removeImageBlobs(ctx, r.blobs, []models.ProductImage{*image})
+		return err
+	}
+	return nil
+}
+
+// ListImages retrieves the images of a live product in display order
+func (r *ProductRepository) ListImages(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images := []models.ProductImage{}
+	query := `SELECT * FROM product_images WHERE product_id = $1 ORDER BY position ASC`
+
+	if err := r.db.SelectContext(ctx, &images, query, productID); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// GetImage retrieves a single image of a live product
+func (r *ProductRepository) GetImage(ctx context.Context, productID, imageID uuid.UUID) (*models.ProductImage, error) {
+	var image models.ProductImage
+	query := `
+		SELECT i.* FROM product_images i
+		JOIN products p ON p.id = i.product_id
+		WHERE i.id = $1 AND i.product_id = $2 AND p.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &image, query, imageID, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// OpenImage retrieves an image of a live product and opens its content, or the content of
+// its thumbnail; callers must close the returned reader
+func (r *ProductRepository) OpenImage(ctx context.Context, productID, imageID uuid.UUID, thumbnail bool) (*models.ProductImage, io.ReadCloser, error) {
+	image, err := r.GetImage(ctx, productID, imageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := openImageBlob(ctx, r.blobs, image, thumbnail)
+	if err != nil {
+		return nil, nil, err
+	}
+	return image, content, nil
+}
+
+// ReorderImages puts the images of a product in the given order. The IDs must list every
+// image of the product exactly once.
+func (r *ProductRepository) ReorderImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]models.ProductImage, error) {
+	images := []models.ProductImage{}
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := r.lockProduct(ctx, tx, productID, false); err != nil {
+			return err
+		}
+
+		var current []models.ProductImage
+		query := `SELECT * FROM product_images WHERE product_id = $1`
+		if err := tx.SelectContext(ctx, &current, query, productID); err != nil {
+			return err
+		}
+		if err := checkImageOrder(current, imageIDs); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE product_images SET position = array_position($2::uuid[], id) - 1
+			WHERE product_id = $1
+		`
+		if _, err := tx.ExecContext(ctx, query, productID, models.UUIDArray(imageIDs)); err != nil {
+			return err
+		}
+
+		query = `SELECT * FROM product_images WHERE product_id = $1 ORDER BY position ASC`
+		return tx.SelectContext(ctx, &images, query, productID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// SetPrimaryImage makes the image the primary image of its product
+func (r *ProductRepository) SetPrimaryImage(ctx context.Context, productID, imageID uuid.UUID) (*models.ProductImage, error) {
+	var image models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := r.lockProduct(ctx, tx, productID, false); err != nil {
+			return err
+		}
+
+		// Clear the previous primary first; the partial unique index allows one per product
+		query := `UPDATE product_images SET is_primary = FALSE WHERE product_id = $1 AND is_primary AND id <> $2`
+		if _, err := tx.ExecContext(ctx, query, productID, imageID); err != nil {
+			return err
+		}
+
+		query = `UPDATE product_images SET is_primary = TRUE WHERE id = $1 AND product_id = $2 RETURNING *`
+		err := tx.GetContext(ctx, &image, query, imageID, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrImageNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// DeleteImage removes an image of a live product together with its content. The next image
+// in display order takes over as primary image. The content is deleted once the row is gone.
+func (r *ProductRepository) DeleteImage(ctx context.Context, productID, imageID uuid.UUID) error {
+	var removed models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := r.lockProduct(ctx, tx, productID, false); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM product_images WHERE id = $1 AND product_id = $2 RETURNING *`
+		err := tx.GetContext(ctx, &removed, query, imageID, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrImageNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE product_images SET position = position - 1 WHERE product_id = $1 AND position > $2`
+		if _, err := tx.ExecContext(ctx, query, productID, removed.Position); err != nil {
+			return err
+		}
+		if !removed.Primary {
+			return nil
+		}
+
+		query = `
+			UPDATE product_images SET is_primary = TRUE
+			WHERE id = (SELECT id FROM product_images WHERE product_id = $1 ORDER BY position ASC LIMIT 1)
+		`
+		_, err = tx.ExecContext(ctx, query, productID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	removeImageBlobs(ctx, r.blobs, []models.ProductImage{removed})
+	return nil
+}
+
+// IncludeRelated embeds the requested related data into the products
+func (r *ProductRepository) IncludeRelated(ctx context.Context, products []models.Product, include models.ProductIncludes) error {
+	return r.includeRelated(ctx, products, include)
+}
+
+// includeRelated embeds variants, price ranges and images as requested
+func (r *ProductRepository) includeRelated(ctx context.Context, products []models.Product, include models.ProductIncludes) error {
+	if include.Variants || include.PriceRange {
+		if err := r.includeVariants(ctx, products, include); err != nil {
+			return err
+		}
+	}
+	if !include.Images || len(products) == 0 {
+		return nil
+	}
+
+	ids := make(models.UUIDArray, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+
+	var images []models.ProductImage
+	query := `SELECT * FROM product_images WHERE product_id = ANY($1::uuid[]) ORDER BY position ASC`
+	if err := r.db.SelectContext(ctx, &images, query, ids); err != nil {
+		return err
+	}
+
+	models.AttachImages(products, images)
+	return nil
+}
+
+// deleteProductImages removes the image rows of products being deleted and returns them, so
+// that their content can be deleted once the transaction has committed
+func deleteProductImages(ctx context.Context, tx *sqlx.Tx, productIDs []uuid.UUID) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+	query := `DELETE FROM product_images WHERE product_id = ANY($1::uuid[]) RETURNING *`
+	err := tx.SelectContext(ctx, &images, query, models.UUIDArray(productIDs))
+	return images, err
+}
+
+// checkImageOrder verifies that the IDs list every current image exactly once
+func checkImageOrder(current []models.ProductImage, imageIDs []uuid.UUID) error {
+	if len(imageIDs) != len(current) {
+		return fmt.Errorf("%w: expected %d image ids, got %d", ErrInvalidImageOrder, len(current), len(imageIDs))
+	}
+
+	known := make(map[uuid.UUID]bool, len(current))
+	for _, image := range current {
+		known[image.ID] = true
+	}
+	for _, id := range imageIDs {
+		if !known[id] {
+			return fmt.Errorf("%w: image %s is not an image of the product or is listed twice", ErrInvalidImageOrder, id)
+		}
+		delete(known, id)
+	}
+	return nil
+}
+
+// storeImageBlobs writes the content and thumbnail of an image, removing what was written
+// if either fails
+func storeImageBlobs(ctx context.Context, blobs storage.BlobStore, image *models.ProductImage, content, thumbnail []byte) error {
+	if err := blobs.Put(ctx, image.BlobKey, bytes.NewReader(content)); err != nil {
+		return err
+	}
+	if err := blobs.Put(ctx, image.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+		removeImageBlobs(ctx, blobs, []models.ProductImage{*image})
+		return err
+	}
+	return nil
+}
+
+// openImageBlob opens the content or thumbnail of an image
+func openImageBlob(ctx context.Context, blobs storage.BlobStore, image *models.ProductImage, thumbnail bool) (io.ReadCloser, error) {
+	key := image.BlobKey
+	if thumbnail {
+		key = image.ThumbnailKey
+	}
+
+	content, err := blobs.Get(ctx, key)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		// The image was deleted after its row was read
+		return nil, ErrImageNotFound
+	}
+	return content, err
+}
+
+// removeImageBlobs deletes the content and thumbnails of images whose rows are already gone.
+// Failures leave orphaned blobs, never images without content, so they are only logged.
+func removeImageBlobs(ctx context.Context, blobs storage.BlobStore, images []models.ProductImage) {
+	for i := range images {
+		for _, key := range images[i].BlobKeys() {
+			if err := blobs.Delete(ctx, key); err != nil {
+				logger.GetLogger().Warn("Failed to delete image blob",
+					zap.Error(err),
+					zap.String("product_id", images[i].ProductID.String()),
+					zap.String("image_id", images[i].ID.String()),
+					zap.String("key", key),
+				)
+			}
+		}
+	}
+}
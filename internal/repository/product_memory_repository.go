@@ -2,30 +2,69 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 
 	"product-service/internal/models"
 	"product-service/pkg/logger"
+	"product-service/pkg/search"
+	"product-service/pkg/storage"
 	"product-service/pkg/utils"
 )
 
 // ProductMemoryRepository handles in-memory operations for products
 type ProductMemoryRepository struct {
-	products []models.Product
-	mutex    sync.RWMutex
-	logger   *zap.Logger
+	products     []models.Product
+	skuIndex     map[string]uuid.UUID
+	tagIndex     map[string]map[uuid.UUID]bool
+	history      map[uuid.UUID][]models.ProductHistory
+	historySeq   int64
+	prices       map[uuid.UUID][]models.PricePoint
+	priceSeq     int64
+	schedules    map[uuid.UUID][]models.PriceSchedule
+	categories   map[uuid.UUID]models.Category
+	variants     map[uuid.UUID][]models.ProductVariant
+	variantSKUs  map[string]uuid.UUID
+	stock        map[stockKey]models.StockLevel
+	movements    map[uuid.UUID][]models.StockMovement
+	movementSeq  int64
+	images       map[uuid.UUID][]models.ProductImage
+	translations map[uuid.UUID]map[string]models.ProductTranslation
+	searchIndex  *search.Index
+	suggestIndex *search.SuggestIndex
+	blobs        storage.BlobStore
+	mutex        sync.RWMutex
+	logger       *zap.Logger
 }
 
-// NewProductMemoryRepository creates a new in-memory repository instance
-func NewProductMemoryRepository() *ProductMemoryRepository {
+// NewProductMemoryRepository creates a new in-memory repository instance that keeps
+// image content in the given blob store
+func NewProductMemoryRepository(blobs storage.BlobStore) *ProductMemoryRepository {
 	return &ProductMemoryRepository{
-		products: make([]models.Product, 0),
-		logger:   logger.GetLogger(),
+		products:     make([]models.Product, 0),
+		skuIndex:     make(map[string]uuid.UUID),
+		tagIndex:     make(map[string]map[uuid.UUID]bool),
+		history:      make(map[uuid.UUID][]models.ProductHistory),
+		prices:       make(map[uuid.UUID][]models.PricePoint),
+		schedules:    make(map[uuid.UUID][]models.PriceSchedule),
+		categories:   make(map[uuid.UUID]models.Category),
+		variants:     make(map[uuid.UUID][]models.ProductVariant),
+		variantSKUs:  make(map[string]uuid.UUID),
+		stock:        make(map[stockKey]models.StockLevel),
+		movements:    make(map[uuid.UUID][]models.StockMovement),
+		images:       make(map[uuid.UUID][]models.ProductImage),
+		translations: make(map[uuid.UUID]map[string]models.ProductTranslation),
+		searchIndex:  search.NewIndex(),
+		suggestIndex: search.NewSuggestIndex(),
+		blobs:        blobs,
+		logger:       logger.GetLogger(),
 	}
 }
 
@@ -34,7 +73,14 @@ func (r *ProductMemoryRepository) Create(ctx context.Context, product *models.Pr
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.products = append(r.products, *product)
+	if err := r.checkSKU(product.SKU, product.ID); err != nil {
+		return err
+	}
+	if err := r.checkProduct(product); err != nil {
+		return err
+	}
+
+	r.storeProduct(ctx, models.HistoryActionCreate, -1, *product)
 	return nil
 }
 
@@ -43,103 +89,532 @@ func (r *ProductMemoryRepository) CreateBulk(ctx context.Context, products []mod
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.products = append(r.products, products...)
+	// Check the whole batch first so a conflict stores nothing
+	batchSKUs := make(map[string]bool, len(products))
+	for _, product := range products {
+		if err := r.checkProduct(&product); err != nil {
+			return err
+		}
+		if product.SKU == "" {
+			continue
+		}
+		if batchSKUs[product.SKU] {
+			return fmt.Errorf("%w: %s", ErrDuplicateSKU, product.SKU)
+		}
+		if err := r.checkSKU(product.SKU, product.ID); err != nil {
+			return err
+		}
+		batchSKUs[product.SKU] = true
+	}
+
+	for _, product := range products {
+		r.storeProduct(ctx, models.HistoryActionCreate, -1, product)
+	}
 	return nil
 }
 
-// GetByID retrieves a product by its UUID
+// GetByID retrieves a non-deleted product by its UUID
 func (r *ProductMemoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	return r.getByID(id, false)
+}
+
+// GetByIDWithDeleted retrieves a product by its UUID even if it has been soft-deleted
+func (r *ProductMemoryRepository) GetByIDWithDeleted(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	return r.getByID(id, true)
+}
+
+// getByID retrieves a product, optionally including tombstones
+func (r *ProductMemoryRepository) getByID(id uuid.UUID, includeDeleted bool) (*models.Product, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	for _, product := range r.products {
-		if product.ID == id {
+		if product.ID == id && (includeDeleted || product.DeletedAt == nil) {
 			productCopy := product // Create a copy to avoid race conditions
 			return &productCopy, nil
 		}
 	}
-	return nil, errors.New("product not found")
+	return nil, ErrProductNotFound
+}
+
+// GetBySKU retrieves the live product carrying the given SKU
+func (r *ProductMemoryRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Look up and copy under one lock so that a concurrent SKU change cannot slip in between
+	id, ok := r.skuIndex[sku]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+	index := r.findIndex(id, false)
+	if index < 0 {
+		return nil, ErrProductNotFound
+	}
+	productCopy := r.products[index]
+	return &productCopy, nil
 }
 
 // List retrieves products with pagination
-func (r *ProductMemoryRepository) List(ctx context.Context, page, pageSize int) ([]models.Product, error) {
+func (r *ProductMemoryRepository) List(ctx context.Context, page, pageSize int, filter models.ProductFilter) ([]models.Product, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
+	matching := r.filterProducts(filter)
+
 	// Calculate start and end indices for pagination
 	startIndex := (page - 1) * pageSize
 	endIndex := startIndex + pageSize
 
 	// Check if startIndex is valid
-	if startIndex >= len(r.products) {
+	if startIndex >= len(matching) {
 		return []models.Product{}, nil
 	}
 
 	// Check if endIndex is valid
-	if endIndex > len(r.products) {
-		endIndex = len(r.products)
+	if endIndex > len(matching) {
+		endIndex = len(matching)
 	}
 
-	// Create a copy of the slice to prevent race conditions
-	result := make([]models.Product, endIndex-startIndex)
-	copy(result, r.products[startIndex:endIndex])
+	result := matching[startIndex:endIndex]
+	if filter.AsOf != nil {
+		r.applyPricesAsOf(result, *filter.AsOf)
+	}
+	if filter.Include.Any() {
+		r.includeRelated(result, filter.Include)
+	}
+	if len(filter.Locales) > 0 {
+		r.applyTranslations(result, filter.Locales)
+	}
 	return result, nil
 }
 
 // GetAll retrieves all products without pagination
-func (r *ProductMemoryRepository) GetAll(ctx context.Context) ([]models.Product, error) {
+func (r *ProductMemoryRepository) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	// Return a copy of the products slice to prevent race conditions
-	result := make([]models.Product, len(r.products))
-	copy(result, r.products)
+	result := r.filterProducts(filter)
+	if filter.AsOf != nil {
+		r.applyPricesAsOf(result, *filter.AsOf)
+	}
+	if filter.Include.Any() {
+		r.includeRelated(result, filter.Include)
+	}
+	if len(filter.Locales) > 0 {
+		r.applyTranslations(result, filter.Locales)
+	}
 	return result, nil
 }
 
-// Update modifies an existing product
-func (r *ProductMemoryRepository) Update(ctx context.Context, id uuid.UUID, req *models.ProductRequest) error {
+// filterProducts returns copies of the products matching the filter; callers must hold the lock
+func (r *ProductMemoryRepository) filterProducts(filter models.ProductFilter) []models.Product {
+	matches := r.productMatcher(filter)
+	result := make([]models.Product, 0, len(r.products))
+	for i := range r.products {
+		if matches(&r.products[i]) {
+			result = append(result, r.products[i])
+		}
+	}
+	return result
+}
+
+// productMatcher compiles the filter into a predicate over in-memory products; callers must hold the lock
+func (r *ProductMemoryRepository) productMatcher(filter models.ProductFilter) func(product *models.Product) bool {
+	var categories map[uuid.UUID]bool
+	if filter.CategoryID != nil {
+		if filter.IncludeDescendants {
+			categories = r.categorySubtree(*filter.CategoryID)
+		} else {
+			categories = map[uuid.UUID]bool{*filter.CategoryID: true}
+		}
+	}
+
+	// The tag index only covers live products, so tombstones are matched on their own tags
+	var tagged map[uuid.UUID]bool
+	if len(filter.Tags) > 0 && !filter.IncludeDeleted {
+		tagged = r.taggedProducts(filter.Tags, filter.TagMode)
+	}
+
+	name := strings.ToLower(filter.Name)
+
+	return func(product *models.Product) bool {
+		if !filter.IncludeDeleted && product.DeletedAt != nil {
+			return false
+		}
+		if name != "" && !strings.Contains(strings.ToLower(product.Name), name) {
+			return false
+		}
+		if filter.MinPrice != nil && product.Price < *filter.MinPrice {
+			return false
+		}
+		if filter.MaxPrice != nil && product.Price > *filter.MaxPrice {
+			return false
+		}
+		if len(filter.Statuses) > 0 && !models.ContainsString(filter.Statuses, product.Status) {
+			return false
+		}
+		if categories != nil && !inAnyCategory(product.CategoryIDs, categories) {
+			return false
+		}
+		if tagged != nil && !tagged[product.ID] {
+			return false
+		}
+		if tagged == nil && len(filter.Tags) > 0 && !hasTags(product.Tags, filter.Tags, filter.TagMode) {
+			return false
+		}
+		for _, attribute := range filter.Attributes {
+			if !attribute.Matches(product.Attributes) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// taggedProducts looks up the live products carrying all or any of the tags; callers must hold the lock
+func (r *ProductMemoryRepository) taggedProducts(tags []string, mode string) map[uuid.UUID]bool {
+	result := make(map[uuid.UUID]bool)
+	if mode == models.TagModeAny {
+		for _, tag := range tags {
+			for id := range r.tagIndex[tag] {
+				result[id] = true
+			}
+		}
+		return result
+	}
+
+	// Intersect starting from the rarest tag
+	smallest := r.tagIndex[tags[0]]
+	for _, tag := range tags[1:] {
+		if len(r.tagIndex[tag]) < len(smallest) {
+			smallest = r.tagIndex[tag]
+		}
+	}
+	for id := range smallest {
+		matched := true
+		for _, tag := range tags {
+			if !r.tagIndex[tag][id] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			result[id] = true
+		}
+	}
+	return result
+}
+
+// hasTags reports whether a product's tags satisfy the tag filter
+func hasTags(productTags, tags []string, mode string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, productTag := range productTags {
+			if productTag == tag {
+				found = true
+				break
+			}
+		}
+		if found && mode == models.TagModeAny {
+			return true
+		}
+		if !found && mode != models.TagModeAny {
+			return false
+		}
+	}
+	return mode != models.TagModeAny
+}
+
+// inAnyCategory reports whether any of the product's categories is in the set
+func inAnyCategory(categoryIDs models.UUIDArray, categories map[uuid.UUID]bool) bool {
+	for _, id := range categoryIDs {
+		if categories[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// Update modifies an existing product and bumps its version.
+// A non-zero expectedVersion makes the write conditional on the stored version.
+func (r *ProductMemoryRepository) Update(ctx context.Context, id uuid.UUID, req *models.ProductRequest, expectedVersion int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := r.findIndex(id, false)
+	if index < 0 {
+		return ErrProductNotFound
+	}
+	if expectedVersion != 0 && r.products[index].Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+
+	_, err := r.replaceProduct(ctx, index, req)
+	return err
+}
+
+// Put replaces the product with the given ID, creating it with that ID when it does not exist.
+// It reports whether the product was created. A non-zero expectedVersion only allows replacing
+// that version, and createOnly only allows creating.
+func (r *ProductMemoryRepository) Put(ctx context.Context, id uuid.UUID, req *models.ProductRequest, expectedVersion int64, createOnly bool) (*models.Product, bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := r.findIndex(id, true)
+	if index < 0 {
+		if expectedVersion != 0 {
+			return nil, false, ErrProductNotFound
+		}
+
+		product := req.ToProduct()
+		product.ID = id
+		if err := r.checkSKU(product.SKU, id); err != nil {
+			return nil, false, err
+		}
+		if err := r.checkProduct(&product); err != nil {
+			return nil, false, err
+		}
+		r.storeProduct(ctx, models.HistoryActionCreate, -1, product)
+		return &product, true, nil
+	}
+
+	// Tombstones keep their ID so they cannot be silently revived
+	if r.products[index].DeletedAt != nil {
+		return nil, false, ErrProductDeleted
+	}
+	if createOnly {
+		return nil, false, ErrProductExists
+	}
+	if expectedVersion != 0 && r.products[index].Version != expectedVersion {
+		return nil, false, ErrVersionMismatch
+	}
+
+	product, err := r.replaceProduct(ctx, index, req)
+	if err != nil {
+		return nil, false, err
+	}
+	return product, false, nil
+}
+
+// UpsertBySKU creates a product for the request's SKU or replaces the live product holding it.
+// It reports whether the product was created.
+func (r *ProductMemoryRepository) UpsertBySKU(ctx context.Context, req *models.ProductRequest) (*models.Product, bool, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	for i, product := range r.products {
-		if product.ID == id {
-			// Update product fields
-			r.products[i].Name = req.Name
-			r.products[i].Description = req.Description
-			r.products[i].Price = req.Price
-			r.products[i].UpdatedAt = time.Now()
-			return nil
+	id, ok := r.skuIndex[req.SKU]
+	if !ok {
+		product := req.ToProduct()
+		if err := r.checkProduct(&product); err != nil {
+			return nil, false, err
 		}
+		r.storeProduct(ctx, models.HistoryActionCreate, -1, product)
+		return &product, true, nil
+	}
+
+	product, err := r.replaceProduct(ctx, r.findIndex(id, false), req)
+	if err != nil {
+		return nil, false, err
 	}
-	return errors.New("product not found")
+	return product, false, nil
 }
 
-// Delete removes a product by its ID
-func (r *ProductMemoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// replaceProduct overwrites the editable fields of a stored product and bumps its version;
+// callers must hold the write lock. A request that changes nothing leaves the product,
+// its version and its history untouched.
+func (r *ProductMemoryRepository) replaceProduct(ctx context.Context, index int, req *models.ProductRequest) (*models.Product, error) {
+	updated := r.products[index]
+	if len(req.Changes(&updated)) == 0 {
+		return &updated, nil
+	}
+
+	req.ApplyTo(&updated)
+	updated.UpdatedAt = time.Now()
+	updated.Version++
+
+	if err := r.checkSKU(updated.SKU, updated.ID); err != nil {
+		return nil, err
+	}
+	if err := r.checkProduct(&updated); err != nil {
+		return nil, err
+	}
+
+	r.storeProduct(ctx, models.HistoryActionUpdate, index, updated)
+	return &updated, nil
+}
+
+// Patch writes only the given fields of an existing product and bumps its version.
+// A non-zero expectedVersion makes the write conditional on the stored version.
+func (r *ProductMemoryRepository) Patch(ctx context.Context, id uuid.UUID, changes map[string]interface{}, expectedVersion int64) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	for i, product := range r.products {
-		if product.ID == id {
-			// Remove the product by swapping with the last element and truncating
-			r.products[i] = r.products[len(r.products)-1]
-			r.products = r.products[:len(r.products)-1]
-			return nil
+	index := r.findIndex(id, false)
+	if index < 0 {
+		return ErrProductNotFound
+	}
+	if expectedVersion != 0 && r.products[index].Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// Apply changes to a copy so a bad column leaves the stored product untouched
+	patched := r.products[index]
+	for column, value := range changes {
+		if err := applyProductChange(&patched, column, value); err != nil {
+			return err
 		}
 	}
-	return errors.New("product not found")
+	patched.UpdatedAt = time.Now()
+	patched.Version++
+
+	if err := r.checkSKU(patched.SKU, id); err != nil {
+		return err
+	}
+	if err := r.checkProduct(&patched); err != nil {
+		return err
+	}
+
+	r.storeProduct(ctx, models.HistoryActionPatch, index, patched)
+	return nil
+}
+
+// applyProductChange sets a single column value on an in-memory product
+func applyProductChange(product *models.Product, column string, value interface{}) error {
+	var ok bool
+	switch column {
+	case "sku":
+		product.SKU, ok = value.(string)
+	case "name":
+		product.Name, ok = value.(string)
+	case "description":
+		product.Description, ok = value.(string)
+	case "price":
+		product.Price, ok = value.(float64)
+	case "category_ids":
+		product.CategoryIDs, ok = value.(models.UUIDArray)
+	case "tags":
+		product.Tags, ok = value.(pq.StringArray)
+	case "attributes":
+		product.Attributes, ok = value.(models.Attributes)
+	default:
+		return fmt.Errorf("column %q cannot be patched", column)
+	}
+	if !ok {
+		return fmt.Errorf("invalid value type %T for column %q", value, column)
+	}
+	return nil
+}
+
+// Delete soft-deletes a product by its ID, leaving a tombstone until it is purged, and removes
+// its images. A non-zero expectedVersion makes the delete conditional on the stored version.
+func (r *ProductMemoryRepository) Delete(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := r.findIndex(id, false)
+	if index < 0 {
+		return ErrProductNotFound
+	}
+	if expectedVersion != 0 && r.products[index].Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+
+	now := time.Now()
+	deleted := r.products[index]
+	deleted.DeletedAt = &now
+	deleted.UpdatedAt = now
+	deleted.Version++
+
+	r.storeProduct(ctx, models.HistoryActionDelete, index, deleted)
+	return nil
+}
+
+// Restore clears the tombstone of a soft-deleted product.
+// A non-zero expectedVersion makes the restore conditional on the stored version.
+func (r *ProductMemoryRepository) Restore(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := r.findIndex(id, true)
+	if index < 0 {
+		return ErrProductNotFound
+	}
+	if r.products[index].DeletedAt == nil {
+		return ErrProductNotDeleted
+	}
+	if expectedVersion != 0 && r.products[index].Version != expectedVersion {
+		return ErrVersionMismatch
+	}
+
+	// Another product may have taken the SKU while this one was deleted
+	if err := r.checkSKU(r.products[index].SKU, id); err != nil {
+		return err
+	}
+
+	restored := r.products[index]
+	restored.DeletedAt = nil
+	restored.UpdatedAt = time.Now()
+	restored.Version++
+
+	r.storeProduct(ctx, models.HistoryActionRestore, index, restored)
+	return nil
 }
 
-// DeleteAll removes all products
+// DeleteAll soft-deletes all products together with their images
 func (r *ProductMemoryRepository) DeleteAll(ctx context.Context) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	r.products = make([]models.Product, 0)
+	now := time.Now()
+	for i := range r.products {
+		if r.products[i].DeletedAt == nil {
+			deleted := r.products[i]
+			deleted.DeletedAt = &now
+			deleted.UpdatedAt = now
+			deleted.Version++
+
+			r.storeProduct(ctx, models.HistoryActionDelete, i, deleted)
+		}
+	}
 	return nil
 }
 
+// PurgeDeleted permanently removes products soft-deleted before the given time, together
+// with their variants, stock levels and translations. Their audit trail and stock ledger are
+// kept. Images are removed when a product is deleted; any still attached are removed here too.
+func (r *ProductMemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Compact the slice in place, keeping insertion order
+	kept := r.products[:0]
+	var purged int64
+	var images []models.ProductImage
+	for _, product := range r.products {
+		if product.DeletedAt != nil && product.DeletedAt.Before(before) {
+			delete(r.prices, product.ID)
+			delete(r.schedules, product.ID)
+			r.removeVariants(product.ID)
+			r.removeStock(product.ID)
+			images = append(images, r.removeImages(product.ID)...)
+			delete(r.translations, product.ID)
+			r.searchIndex.Remove(product.ID.String())
+			r.suggestIndex.Remove(product.ID.String())
+			purged++
+			continue
+		}
+		kept = append(kept, product)
+	}
+	r.products = kept
+
+	removeImageBlobs(ctx, r.blobs, images)
+	return purged, nil
+}
+
 // GenerateAndSaveBulkProducts creates a specified number of random products
 func (r *ProductMemoryRepository) GenerateAndSaveBulkProducts(ctx context.Context, count int) error {
 	// Generate random products
@@ -154,8 +629,13 @@ func (r *ProductMemoryRepository) GenerateAndSaveBulkProducts(ctx context.Contex
 			Name:        rp.Name,
 			Description: rp.Description,
 			Price:       rp.Price,
+			CategoryIDs: models.UUIDArray{},
+			Tags:        pq.StringArray{},
+			Attributes:  models.Attributes{},
+			Status:      models.StatusActive,
 			CreatedAt:   now,
 			UpdatedAt:   now,
+			Version:     1,
 		}
 	}
 
@@ -163,10 +643,116 @@ func (r *ProductMemoryRepository) GenerateAndSaveBulkProducts(ctx context.Contex
 	return r.CreateBulk(ctx, products)
 }
 
-// Count returns the total number of products
-func (r *ProductMemoryRepository) Count(ctx context.Context) (int, error) {
+// Count returns the total number of products matching the filter
+func (r *ProductMemoryRepository) Count(ctx context.Context, filter models.ProductFilter) (int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	matches := r.productMatcher(filter)
+	count := 0
+	for i := range r.products {
+		if matches(&r.products[i]) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// TagCounts returns how many products matching the filter carry each tag, most used first
+func (r *ProductMemoryRepository) TagCounts(ctx context.Context, filter models.ProductFilter) ([]models.TagCount, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	return len(r.products), nil
+	counts := make(map[string]int)
+	matches := r.productMatcher(filter)
+	for i := range r.products {
+		if matches(&r.products[i]) {
+			for _, tag := range r.products[i].Tags {
+				counts[tag]++
+			}
+		}
+	}
+
+	result := make([]models.TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, models.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result, nil
+}
+
+// findIndex returns the slot of a product, or -1; callers must hold the lock
+func (r *ProductMemoryRepository) findIndex(id uuid.UUID, includeDeleted bool) int {
+	for i := range r.products {
+		if r.products[i].ID == id && (includeDeleted || r.products[i].DeletedAt == nil) {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkSKU returns ErrDuplicateSKU when another live product holds the SKU; callers must hold the lock
+func (r *ProductMemoryRepository) checkSKU(sku string, id uuid.UUID) error {
+	if sku == "" {
+		return nil
+	}
+	if owner, ok := r.skuIndex[sku]; ok && owner != id {
+		return fmt.Errorf("%w: %s", ErrDuplicateSKU, sku)
+	}
+	return nil
+}
+
+// storeProduct writes a product and records the change; callers must hold the write lock.
+// index is the slot of the product being replaced, or -1 to append a new product.
+func (r *ProductMemoryRepository) storeProduct(ctx context.Context, action string, index int, product models.Product) {
+	var before *models.Product
+	if index >= 0 {
+		previous := r.products[index]
+		before = &previous
+		r.products[index] = product
+	} else {
+		r.products = append(r.products, product)
+	}
+
+	// Keep the SKU index in step with the live products
+	if before != nil && before.DeletedAt == nil && before.SKU != "" {
+		delete(r.skuIndex, before.SKU)
+	}
+	if product.DeletedAt == nil && product.SKU != "" {
+		r.skuIndex[product.SKU] = product.ID
+	}
+
+	// Keep the tag index in step with the live products
+	if before != nil && before.DeletedAt == nil {
+		for _, tag := range before.Tags {
+			delete(r.tagIndex[tag], before.ID)
+			if len(r.tagIndex[tag]) == 0 {
+				delete(r.tagIndex, tag)
+			}
+		}
+	}
+	if product.DeletedAt == nil {
+		for _, tag := range product.Tags {
+			if r.tagIndex[tag] == nil {
+				r.tagIndex[tag] = make(map[uuid.UUID]bool)
+			}
+			r.tagIndex[tag][product.ID] = true
+		}
+	}
+
+	// Tombstones stay searchable for includeDeleted; the filter hides them otherwise
+	r.indexProduct(&product)
+
+	// Deleting a product removes its images; restoring it does not bring them back
+	if product.DeletedAt != nil && (before == nil || before.DeletedAt == nil) {
+		removeImageBlobs(ctx, r.blobs, r.removeImages(product.ID))
+	}
+
+	r.recordHistory(newHistoryEntry(ctx, action, before, &product))
+	r.recordPriceChange(before, &product)
 }
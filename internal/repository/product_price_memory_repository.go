@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// recordPriceChange closes the open price point and opens a new one when the price changed;
+// callers must hold the write lock
+func (r *ProductMemoryRepository) recordPriceChange(before, after *models.Product) {
+	if before != nil && before.Price == after.Price {
+		return
+	}
+
+	points := r.prices[after.ID]
+	if n := len(points); n > 0 && points[n-1].EffectiveTo == nil {
+		effectiveTo := after.UpdatedAt
+		points[n-1].EffectiveTo = &effectiveTo
+	}
+
+	r.priceSeq++
+	r.prices[after.ID] = append(points, models.PricePoint{
+		ID:            r.priceSeq,
+		ProductID:     after.ID,
+		Price:         after.Price,
+		EffectiveFrom: after.UpdatedAt,
+	})
+}
+
+// GetPriceHistory retrieves the price series of a product, oldest first
+func (r *ProductMemoryRepository) GetPriceHistory(ctx context.Context, productID uuid.UUID) ([]models.PricePoint, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	points := r.prices[productID]
+	result := make([]models.PricePoint, len(points))
+	copy(result, points)
+	return result, nil
+}
+
+// ApplyPricesAsOf replaces each product's price with the price effective at the given moment.
+// Products without a recorded price at that moment are marked PriceUnknown.
+func (r *ProductMemoryRepository) ApplyPricesAsOf(ctx context.Context, products []models.Product, asOf time.Time) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.applyPricesAsOf(products, asOf)
+	return nil
+}
+
+// applyPricesAsOf overwrites product prices; callers must hold the lock
+func (r *ProductMemoryRepository) applyPricesAsOf(products []models.Product, asOf time.Time) {
+	for i := range products {
+		products[i].PriceUnknown = true
+		for _, point := range r.prices[products[i].ID] {
+			if point.EffectiveAt(asOf) {
+				products[i].Price = point.Price
+				products[i].PriceUnknown = false
+				break
+			}
+		}
+	}
+}
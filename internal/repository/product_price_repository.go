@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"product-service/internal/models"
+)
+
+// priceBatchSize bounds the rows per multi-row price insert
+const priceBatchSize = 1000
+
+// recordPriceChange closes the open price point and opens a new one when the price changed
+func (r *ProductRepository) recordPriceChange(ctx context.Context, tx *sqlx.Tx, before, after *models.Product) error {
+	if before != nil && before.Price == after.Price {
+		return nil
+	}
+
+	if before != nil {
+		query := `UPDATE product_prices SET effective_to = $2 WHERE product_id = $1 AND effective_to IS NULL`
+		if _, err := tx.ExecContext(ctx, query, after.ID, after.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	return r.insertPricePoints(ctx, tx, models.PricePoint{
+		ProductID:     after.ID,
+		Price:         after.Price,
+		EffectiveFrom: after.UpdatedAt,
+	})
+}
+
+// insertPricePoints writes price points as part of the caller's transaction
+func (r *ProductRepository) insertPricePoints(ctx context.Context, tx *sqlx.Tx, points ...models.PricePoint) error {
+	query := `
+		INSERT INTO product_prices 
+		(product_id, price, effective_from, effective_to) 
+		VALUES (:product_id, :price, :effective_from, :effective_to)
+	`
+
+	for start := 0; start < len(points); start += priceBatchSize {
+		end := start + priceBatchSize
+		if end > len(points) {
+			end = len(points)
+		}
+		if _, err := tx.NamedExecContext(ctx, query, points[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPriceHistory retrieves the price series of a product, oldest first
+func (r *ProductRepository) GetPriceHistory(ctx context.Context, productID uuid.UUID) ([]models.PricePoint, error) {
+	var points []models.PricePoint
+	query := `SELECT * FROM product_prices WHERE product_id = $1 ORDER BY effective_from ASC, id ASC`
+
+	err := r.db.SelectContext(ctx, &points, query, productID)
+	return points, err
+}
+
+// ApplyPricesAsOf replaces each product's price with the price effective at the given moment.
+// Products without a recorded price at that moment are marked PriceUnknown.
+func (r *ProductRepository) ApplyPricesAsOf(ctx context.Context, products []models.Product, asOf time.Time) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID.String()
+	}
+
+	var points []models.PricePoint
+	query := `
+		SELECT * FROM product_prices 
+		WHERE product_id = ANY($1::uuid[]) 
+			AND effective_from <= $2 
+			AND (effective_to IS NULL OR effective_to > $2)
+	`
+	if err := r.db.SelectContext(ctx, &points, query, pq.Array(ids), asOf); err != nil {
+		return err
+	}
+
+	applyPricePoints(products, points)
+	return nil
+}
+
+// applyPricePoints overwrites product prices with the matching price points and marks the
+// products without one PriceUnknown
+func applyPricePoints(products []models.Product, points []models.PricePoint) {
+	prices := make(map[uuid.UUID]float64, len(points))
+	for _, point := range points {
+		prices[point.ProductID] = point.Price
+	}
+	for i := range products {
+		price, ok := prices[products[i].ID]
+		if ok {
+			products[i].Price = price
+		}
+		products[i].PriceUnknown = !ok
+	}
+}
@@ -2,72 +2,168 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 
 	"product-service/internal/models"
+	"product-service/pkg/storage"
 	"product-service/pkg/utils"
 )
 
+// patchableColumns lists the product columns that Patch is allowed to write
+var patchableColumns = map[string]bool{
+	"sku":          true,
+	"name":         true,
+	"description":  true,
+	"price":        true,
+	"category_ids": true,
+	"tags":         true,
+	"attributes":   true,
+}
+
+const (
+	// skuIndexName is the unique index that enforces SKU uniqueness among live products
+	skuIndexName = "idx_product_sku"
+
+	// variantSKUIndexName is the unique index that enforces SKU uniqueness among variants
+	variantSKUIndexName = "idx_product_variant_sku"
+
+	// uniqueViolation is the Postgres SQLSTATE raised when a unique index rejects a write
+	uniqueViolation = "23505"
+)
+
+const (
+	// insertProductQuery inserts a complete product row
+	insertProductQuery = `
+		INSERT INTO products 
+		(id, sku, name, description, price, category_ids, tags, attributes, status, created_at, updated_at, version) 
+		VALUES (:id, :sku, :name, :description, :price, :category_ids, :tags, :attributes, :status, :created_at, :updated_at, :version)
+	`
+
+	// insertProductIfAbsentQuery inserts a product unless its ID is already taken
+	insertProductIfAbsentQuery = insertProductQuery + `ON CONFLICT (id) DO NOTHING`
+
+	// insertProductIfSKUAbsentQuery inserts a product unless a live product already holds its SKU
+	insertProductIfSKUAbsentQuery = insertProductQuery + `ON CONFLICT (sku) WHERE sku <> '' AND deleted_at IS NULL DO NOTHING`
+
+	// updateProductQuery overwrites the editable columns of a locked product row
+	updateProductQuery = `
+		UPDATE products 
+		SET sku = :sku,
+			name = :name, 
+			description = :description, 
+			price = :price, 
+			category_ids = :category_ids,
+			tags = :tags,
+			attributes = :attributes,
+			status = :status,
+			updated_at = :updated_at,
+			deleted_at = :deleted_at,
+			version = :version 
+		WHERE id = :id
+	`
+)
+
 // ProductRepository handles database operations for products
 type ProductRepository struct {
-	db *sqlx.DB
+	db    *sqlx.DB
+	blobs storage.BlobStore
 }
 
-// NewProductRepository creates a new repository instance
-func NewProductRepository(db *sqlx.DB) *ProductRepository {
-	return &ProductRepository{db: db}
+// NewProductRepository creates a new repository instance that keeps image content in the given blob store
+func NewProductRepository(db *sqlx.DB, blobs storage.BlobStore) *ProductRepository {
+	return &ProductRepository{db: db, blobs: blobs}
 }
 
 // Create inserts a new product into the database
 func (r *ProductRepository) Create(ctx context.Context, product *models.Product) error {
-	query := `
-		INSERT INTO products 
-		(id, name, description, price, created_at, updated_at) 
-		VALUES (:id, :name, :description, :price, :created_at, :updated_at)
-	`
-	_, err := r.db.NamedExecContext(ctx, query, product)
-	return err
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		return r.insertProduct(ctx, tx, product)
+	})
 }
 
-// CreateBulk inserts multiple products in a single transaction
-func (r *ProductRepository) CreateBulk(ctx context.Context, products []models.Product) error {
-	// Start a transaction
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
+// insertProduct checks and inserts a new product and records its creation
+func (r *ProductRepository) insertProduct(ctx context.Context, tx *sqlx.Tx, product *models.Product) error {
+	if err := r.checkProduct(ctx, tx, product); err != nil {
 		return err
 	}
+	if _, err := tx.NamedExecContext(ctx, insertProductQuery, product); err != nil {
+		return err
+	}
+	return r.recordChange(ctx, tx, models.HistoryActionCreate, nil, product)
+}
 
-	// Prepare the query
-	query := `
-		INSERT INTO products 
-		(id, name, description, price, created_at, updated_at) 
-		VALUES (:id, :name, :description, :price, :created_at, :updated_at)
-	`
+// CreateBulk inserts multiple products in a single transaction
+func (r *ProductRepository) CreateBulk(ctx context.Context, products []models.Product) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		entries := make([]models.ProductHistory, len(products))
+		points := make([]models.PricePoint, len(products))
 
-	// Execute bulk insert
-	for _, product := range products {
-		_, err := tx.NamedExecContext(ctx, query, product)
-		if err != nil {
-			tx.Rollback()
-			return err
+		// Execute bulk insert
+		for i := range products {
+			if err := r.checkProduct(ctx, tx, &products[i]); err != nil {
+				return err
+			}
+			if _, err := tx.NamedExecContext(ctx, insertProductQuery, products[i]); err != nil {
+				return err
+			}
+			entries[i] = newHistoryEntry(ctx, models.HistoryActionCreate, nil, &products[i])
+			points[i] = models.PricePoint{
+				ProductID:     products[i].ID,
+				Price:         products[i].Price,
+				EffectiveFrom: products[i].CreatedAt,
+			}
 		}
-	}
 
-	// Commit the transaction
-	return tx.Commit()
+		if err := r.recordHistory(ctx, tx, entries...); err != nil {
+			return err
+		}
+		return r.insertPricePoints(ctx, tx, points...)
+	})
 }
 
-// GetByID retrieves a product by its UUID
+// GetByID retrieves a non-deleted product by its UUID
 func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	return r.getByID(ctx, id, false)
+}
+
+// GetByIDWithDeleted retrieves a product by its UUID even if it has been soft-deleted
+func (r *ProductRepository) GetByIDWithDeleted(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	return r.getByID(ctx, id, true)
+}
+
+// getByID retrieves a product, optionally including tombstones
+func (r *ProductRepository) getByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*models.Product, error) {
 	var product models.Product
-	query := `SELECT * FROM products WHERE id = $1`
+	query := `SELECT * FROM products WHERE id = $1 AND ($2 OR deleted_at IS NULL)`
 
-	err := r.db.GetContext(ctx, &product, query, id)
+	err := r.db.GetContext(ctx, &product, query, id, includeDeleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetBySKU retrieves the live product carrying the given SKU
+func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	var product models.Product
+	query := `SELECT * FROM products WHERE sku = $1 AND deleted_at IS NULL`
+
+	err := r.db.GetContext(ctx, &product, query, sku)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -75,74 +171,567 @@ func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.
 }
 
 // List retrieves all products with optional pagination
-func (r *ProductRepository) List(ctx context.Context, page, pageSize int) ([]models.Product, error) {
+func (r *ProductRepository) List(ctx context.Context, page, pageSize int, filter models.ProductFilter) ([]models.Product, error) {
 	var products []models.Product
-	query := `
+	where, args := buildProductFilter(filter)
+	query := fmt.Sprintf(`
 		SELECT * FROM products 
+		%s
 		ORDER BY created_at DESC 
-		LIMIT $1 OFFSET $2
-	`
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
 
 	offset := (page - 1) * pageSize
-	err := r.db.SelectContext(ctx, &products, query, pageSize, offset)
-	return products, err
+	args = append(args, pageSize, offset)
+	if err := r.db.SelectContext(ctx, &products, query, args...); err != nil {
+		return nil, err
+	}
+
+	if filter.AsOf != nil {
+		if err := r.ApplyPricesAsOf(ctx, products, *filter.AsOf); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Include.Any() {
+		if err := r.includeRelated(ctx, products, filter.Include); err != nil {
+			return nil, err
+		}
+	}
+	if len(filter.Locales) > 0 {
+		if err := r.ApplyTranslations(ctx, products, filter.Locales); err != nil {
+			return nil, err
+		}
+	}
+	return products, nil
 }
 
 // GetAll retrieves all products without pagination
-func (r *ProductRepository) GetAll(ctx context.Context) ([]models.Product, error) {
+func (r *ProductRepository) GetAll(ctx context.Context, filter models.ProductFilter) ([]models.Product, error) {
 	var products []models.Product
-	query := `SELECT * FROM products ORDER BY created_at DESC`
+	where, args := buildProductFilter(filter)
+	query := fmt.Sprintf(`SELECT * FROM products %s ORDER BY created_at DESC`, where)
 
-	err := r.db.SelectContext(ctx, &products, query)
-	return products, err
+	if err := r.db.SelectContext(ctx, &products, query, args...); err != nil {
+		return nil, err
+	}
+
+	if filter.AsOf != nil {
+		if err := r.ApplyPricesAsOf(ctx, products, *filter.AsOf); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Include.Any() {
+		if err := r.includeRelated(ctx, products, filter.Include); err != nil {
+			return nil, err
+		}
+	}
+	if len(filter.Locales) > 0 {
+		if err := r.ApplyTranslations(ctx, products, filter.Locales); err != nil {
+			return nil, err
+		}
+	}
+	return products, nil
 }
 
-// Update modifies an existing product
-func (r *ProductRepository) Update(ctx context.Context, id uuid.UUID, req *models.ProductRequest) error {
-	query := `
-		UPDATE products 
-		SET name = $1, 
-			description = $2, 
-			price = $3, 
-			updated_at = $4 
-		WHERE id = $5
-	`
+// buildProductFilter translates a filter into a WHERE clause with positional arguments
+func buildProductFilter(filter models.ProductFilter) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if len(filter.Statuses) > 0 {
+		args = append(args, pq.Array(filter.Statuses))
+		conditions = append(conditions, fmt.Sprintf("status = ANY($%d::text[])", len(args)))
+	}
+
+	if filter.Name != "" {
+		args = append(args, filter.Name)
+		conditions = append(conditions, fmt.Sprintf("strpos(lower(name), lower($%d)) > 0", len(args)))
+	}
+
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("price >= $%d", len(args)))
+	}
+
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("price <= $%d", len(args)))
+	}
+
+	if filter.CategoryID != nil {
+		args = append(args, *filter.CategoryID)
+		if filter.IncludeDescendants {
+			subtree := categorySubtree(fmt.Sprintf("$%d", len(args)))
+			conditions = append(conditions, fmt.Sprintf("category_ids && ARRAY(%s)", subtree))
+		} else {
+			conditions = append(conditions, fmt.Sprintf("category_ids @> ARRAY[$%d::uuid]", len(args)))
+		}
+	}
+
+	if len(filter.Tags) > 0 {
+		operator := "@>"
+		if filter.TagMode == models.TagModeAny {
+			operator = "&&"
+		}
+		args = append(args, pq.Array(filter.Tags))
+		conditions = append(conditions, fmt.Sprintf("tags %s $%d::text[]", operator, len(args)))
+	}
+
+	for _, attribute := range filter.Attributes {
+		args = append(args, attribute.Key)
+		key := len(args)
+
+		switch attribute.Op {
+		case models.AttributeOpEq, models.AttributeOpNe:
+			condition := attributeEquals(attribute, key, &args)
+			if attribute.Op == models.AttributeOpNe {
+				condition = "NOT " + condition
+			}
+			conditions = append(conditions, condition)
+		default:
+			args = append(args, attribute.Number)
+			conditions = append(conditions, fmt.Sprintf(
+				"CASE WHEN jsonb_typeof(attributes->$%d) = 'number' THEN (attributes->>$%d)::numeric END %s $%d",
+				key, key, attributeOperators[attribute.Op], len(args)))
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// attributeOperators maps attribute filter range operators to SQL
+var attributeOperators = map[string]string{
+	models.AttributeOpGt:  ">",
+	models.AttributeOpGte: ">=",
+	models.AttributeOpLt:  "<",
+	models.AttributeOpLte: "<=",
+}
+
+// attributeEquals builds a typed containment test for an attribute equality filter.
+// The value is tried as a string and, where it parses, as a number and a boolean, so
+// the GIN index on attributes serves the lookup.
+func attributeEquals(attribute models.AttributeFilter, key int, args *[]interface{}) string {
+	*args = append(*args, attribute.Value)
+	value := len(*args)
+	alternatives := []string{
+		fmt.Sprintf("attributes @> jsonb_build_object($%d::text, $%d::text)", key, value),
+	}
+	if _, ok := attribute.NumberValue(); ok {
+		alternatives = append(alternatives,
+			fmt.Sprintf("attributes @> jsonb_build_object($%d::text, $%d::numeric)", key, value))
+	}
+	if _, ok := attribute.BoolValue(); ok {
+		alternatives = append(alternatives,
+			fmt.Sprintf("attributes @> jsonb_build_object($%d::text, $%d::boolean)", key, value))
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// Update modifies an existing product and bumps its version.
+// A non-zero expectedVersion makes the write conditional on the stored version.
+func (r *ProductRepository) Update(ctx context.Context, id uuid.UUID, req *models.ProductRequest, expectedVersion int64) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := r.updateProduct(ctx, tx, id, req, expectedVersion)
+		return err
+	})
+}
 
-	_, err := r.db.ExecContext(ctx, query,
-		req.Name,
-		req.Description,
-		req.Price,
-		time.Now(),
-		id,
+// updateProduct locks a live product and replaces its editable fields
+func (r *ProductRepository) updateProduct(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, req *models.ProductRequest, expectedVersion int64) (*models.Product, error) {
+	before, err := r.lockProduct(ctx, tx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != 0 && before.Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+	return r.replaceProduct(ctx, tx, before, req)
+}
+
+// Put replaces the product with the given ID, creating it with that ID when it does not exist.
+// It reports whether the product was created. A non-zero expectedVersion only allows replacing
+// that version, and createOnly only allows creating.
+func (r *ProductRepository) Put(ctx context.Context, id uuid.UUID, req *models.ProductRequest, expectedVersion int64, createOnly bool) (*models.Product, bool, error) {
+	var (
+		product *models.Product
+		created bool
 	)
-	return err
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if expectedVersion == 0 {
+			candidate := req.ToProduct()
+			candidate.ID = id
+
+			inserted, err := r.insertIfAbsent(ctx, tx, insertProductIfAbsentQuery, &candidate)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				product, created = &candidate, true
+				return nil
+			}
+		}
+
+		// The ID is taken; tombstones are locked too so they cannot be silently revived
+		before, err := r.lockProduct(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return ErrProductDeleted
+		}
+		if createOnly {
+			return ErrProductExists
+		}
+		if expectedVersion != 0 && before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+
+		product, err = r.replaceProduct(ctx, tx, before, req)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return product, created, nil
 }
 
-// Delete removes a product by its ID
-func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM products WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+// UpsertBySKU creates a product for the request's SKU or replaces the live product holding it.
+// It reports whether the product was created.
+func (r *ProductRepository) UpsertBySKU(ctx context.Context, req *models.ProductRequest) (*models.Product, bool, error) {
+	var (
+		product *models.Product
+		created bool
+	)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		candidate := req.ToProduct()
+		inserted, err := r.insertIfAbsent(ctx, tx, insertProductIfSKUAbsentQuery, &candidate)
+		if err != nil {
+			return err
+		}
+		if inserted {
+			product, created = &candidate, true
+			return nil
+		}
+
+		var before models.Product
+		query := `SELECT * FROM products WHERE sku = $1 AND deleted_at IS NULL FOR UPDATE`
+		err = tx.GetContext(ctx, &before, query, req.SKU)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The conflicting product was deleted between the insert and the lock
+			return ErrVersionMismatch
+		}
+		if err != nil {
+			return err
+		}
+
+		product, err = r.replaceProduct(ctx, tx, &before, req)
+		return err
+	})
 	if err != nil {
+		return nil, false, err
+	}
+	return product, created, nil
+}
+
+// checkProduct verifies the categories a product references and validates its attributes
+// against their schemas
+func (r *ProductRepository) checkProduct(ctx context.Context, tx *sqlx.Tx, product *models.Product) error {
+	if err := r.checkCategories(ctx, tx, product.CategoryIDs); err != nil {
 		return err
 	}
+	return r.checkAttributes(ctx, tx, product)
+}
 
-	rowsAffected, err := result.RowsAffected()
+// insertIfAbsent runs an ON CONFLICT DO NOTHING insert and records the creation when a row was written
+func (r *ProductRepository) insertIfAbsent(ctx context.Context, tx *sqlx.Tx, query string, product *models.Product) (bool, error) {
+	if err := r.checkProduct(ctx, tx, product); err != nil {
+		return false, err
+	}
+
+	result, err := tx.NamedExecContext(ctx, query, product)
 	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil || inserted == 0 {
+		return false, err
+	}
+	return true, r.recordChange(ctx, tx, models.HistoryActionCreate, nil, product)
+}
+
+// replaceProduct overwrites the editable fields of a locked product and bumps its version.
+// A request that changes nothing leaves the product, its version and its history untouched.
+func (r *ProductRepository) replaceProduct(ctx context.Context, tx *sqlx.Tx, before *models.Product, req *models.ProductRequest) (*models.Product, error) {
+	if len(req.Changes(before)) == 0 {
+		return before, nil
+	}
+
+	after := *before
+	req.ApplyTo(&after)
+	after.UpdatedAt = time.Now()
+	after.Version++
+
+	if !after.CategoryIDs.Equal(before.CategoryIDs) || !after.Attributes.Equal(before.Attributes) {
+		if err := r.checkProduct(ctx, tx, &after); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.NamedExecContext(ctx, updateProductQuery, after); err != nil {
+		return nil, err
+	}
+	if err := r.recordChange(ctx, tx, models.HistoryActionUpdate, before, &after); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// Patch writes only the given columns of an existing product and bumps its version.
+// A non-zero expectedVersion makes the write conditional on the stored version.
+func (r *ProductRepository) Patch(ctx context.Context, id uuid.UUID, changes map[string]interface{}, expectedVersion int64) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := r.lockProduct(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != 0 && before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+		_, err = r.patchProduct(ctx, tx, before, changes)
 		return err
+	})
+}
+
+// patchProduct writes the given columns of a locked product, bumps its version and records
+// the change. Without changes it returns the product untouched.
+func (r *ProductRepository) patchProduct(ctx context.Context, tx *sqlx.Tx, before *models.Product, changes map[string]interface{}) (*models.Product, error) {
+	if len(changes) == 0 {
+		return before, nil
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("no product found with the given ID")
+	// Sort columns so the generated statement is stable
+	columns := make([]string, 0, len(changes))
+	for column := range changes {
+		if !patchableColumns[column] {
+			return nil, fmt.Errorf("column %q cannot be patched", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	assignments := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns)+3)
+	for _, column := range columns {
+		args = append(args, changes[column])
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if categoryIDs, ok := changes["category_ids"].(models.UUIDArray); ok {
+		if err := r.checkCategories(ctx, tx, categoryIDs); err != nil {
+			return nil, err
+		}
 	}
 
+	args = append(args, time.Now(), before.Version+1, before.ID)
+	query := fmt.Sprintf(`
+		UPDATE products 
+		SET %s, 
+			updated_at = $%d,
+			version = $%d 
+		WHERE id = $%d
+		RETURNING *
+	`, strings.Join(assignments, ", "), len(args)-2, len(args)-1, len(args))
+
+	var after models.Product
+	if err := tx.GetContext(ctx, &after, query, args...); err != nil {
+		return nil, err
+	}
+
+	// Attributes are checked against the patched row; a failure rolls the write back
+	_, categoriesChanged := changes["category_ids"]
+	if _, attributesChanged := changes["attributes"]; attributesChanged || categoriesChanged {
+		if err := r.checkAttributes(ctx, tx, &after); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.recordChange(ctx, tx, models.HistoryActionPatch, before, &after); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// Delete soft-deletes a product by its ID, leaving a tombstone until it is purged. Its images
+// are removed with it, and their content once the tombstone is committed.
+// A non-zero expectedVersion makes the delete conditional on the stored version.
+func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	var images []models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		_, images, err = r.deleteProduct(ctx, tx, id, expectedVersion)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	removeImageBlobs(ctx, r.blobs, images)
 	return nil
 }
 
-// DeleteAll removes all products from the database
+// deleteProduct locks a live product, leaves a tombstone in its place and removes its image
+// rows. It returns the removed images, whose content the caller deletes after committing.
+func (r *ProductRepository) deleteProduct(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, expectedVersion int64) (*models.Product, []models.ProductImage, error) {
+	before, err := r.lockProduct(ctx, tx, id, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if expectedVersion != 0 && before.Version != expectedVersion {
+		return nil, nil, ErrVersionMismatch
+	}
+
+	now := time.Now()
+	after := *before
+	after.DeletedAt = &now
+	after.UpdatedAt = now
+	after.Version++
+
+	if _, err := tx.NamedExecContext(ctx, updateProductQuery, after); err != nil {
+		return nil, nil, err
+	}
+	if err := r.recordChange(ctx, tx, models.HistoryActionDelete, before, &after); err != nil {
+		return nil, nil, err
+	}
+	images, err := deleteProductImages(ctx, tx, []uuid.UUID{id})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &after, images, nil
+}
+
+// Restore clears the tombstone of a soft-deleted product.
+// A non-zero expectedVersion makes the restore conditional on the stored version.
+func (r *ProductRepository) Restore(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := r.lockProduct(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return ErrProductNotDeleted
+		}
+		if expectedVersion != 0 && before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+
+		after := *before
+		after.DeletedAt = nil
+		after.UpdatedAt = time.Now()
+		after.Version++
+
+		if _, err := tx.NamedExecContext(ctx, updateProductQuery, after); err != nil {
+			return err
+		}
+		return r.recordChange(ctx, tx, models.HistoryActionRestore, before, &after)
+	})
+}
+
+// DeleteAll soft-deletes all products in the database together with their images
 func (r *ProductRepository) DeleteAll(ctx context.Context) error {
-	query := `DELETE FROM products`
-	_, err := r.db.ExecContext(ctx, query)
-	return err
+	var images []models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var products []models.Product
+		query := `SELECT * FROM products WHERE deleted_at IS NULL FOR UPDATE`
+		if err := tx.SelectContext(ctx, &products, query); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		query = `
+			UPDATE products 
+			SET deleted_at = $1, 
+				updated_at = $1,
+				version = version + 1 
+			WHERE deleted_at IS NULL
+		`
+		if _, err := tx.ExecContext(ctx, query, now); err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(products))
+		entries := make([]models.ProductHistory, len(products))
+		for i := range products {
+			after := products[i]
+			after.DeletedAt = &now
+			after.UpdatedAt = now
+			after.Version++
+			ids[i] = products[i].ID
+			entries[i] = newHistoryEntry(ctx, models.HistoryActionDelete, &products[i], &after)
+		}
+		if err := r.recordHistory(ctx, tx, entries...); err != nil {
+			return err
+		}
+
+		var err error
+		images, err = deleteProductImages(ctx, tx, ids)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	removeImageBlobs(ctx, r.blobs, images)
+	return nil
+}
+
+// PurgeDeleted permanently removes products soft-deleted before the given time; their variants,
+// stock levels and translations go with them through the foreign keys. Their audit trail and
+// stock ledger are kept. Images are removed when a product is deleted; the content of any still
+// attached to a tombstone is deleted here, after the rows are gone.
+func (r *ProductRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	var images []models.ProductImage
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Lock the tombstones so that none is restored between reading its images and deleting it
+		var ids []uuid.UUID
+		query := `SELECT id FROM products WHERE deleted_at IS NOT NULL AND deleted_at < $1 FOR UPDATE`
+		if err := tx.SelectContext(ctx, &ids, query, before); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		query = `SELECT * FROM product_images WHERE product_id = ANY($1::uuid[])`
+		if err := tx.SelectContext(ctx, &images, query, models.UUIDArray(ids)); err != nil {
+			return err
+		}
+
+		query = `DELETE FROM products WHERE id = ANY($1::uuid[])`
+		result, err := tx.ExecContext(ctx, query, models.UUIDArray(ids))
+		if err != nil {
+			return err
+		}
+		purged, err = result.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	removeImageBlobs(ctx, r.blobs, images)
+	return purged, nil
 }
 
 // GenerateAndSaveBulkProducts creates a specified number of random products
@@ -158,8 +747,13 @@ func (r *ProductRepository) GenerateAndSaveBulkProducts(ctx context.Context, cou
 			Name:        rp.Name,
 			Description: rp.Description,
 			Price:       rp.Price,
+			CategoryIDs: models.UUIDArray{},
+			Tags:        pq.StringArray{},
+			Attributes:  models.Attributes{},
+			Status:      models.StatusActive,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
+			Version:     1,
 		}
 	}
 
@@ -167,14 +761,86 @@ func (r *ProductRepository) GenerateAndSaveBulkProducts(ctx context.Context, cou
 	return r.CreateBulk(ctx, products)
 }
 
-// Count returns the total number of products in the database
-func (r *ProductRepository) Count(ctx context.Context) (int, error) {
+// Count returns the total number of products in the database matching the filter
+func (r *ProductRepository) Count(ctx context.Context, filter models.ProductFilter) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM products`
+	where, args := buildProductFilter(filter)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM products %s`, where)
 
-	err := r.db.GetContext(ctx, &count, query)
+	err := r.db.GetContext(ctx, &count, query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("error counting products: %v", err)
 	}
 	return count, nil
 }
+
+// TagCounts returns how many products matching the filter carry each tag, most used first
+func (r *ProductRepository) TagCounts(ctx context.Context, filter models.ProductFilter) ([]models.TagCount, error) {
+	counts := []models.TagCount{}
+	where, args := buildProductFilter(filter)
+	query := fmt.Sprintf(`
+		SELECT tag, COUNT(*) AS count 
+		FROM products, unnest(tags) AS tag 
+		%s
+		GROUP BY tag 
+		ORDER BY count DESC, tag ASC
+	`, where)
+
+	if err := r.db.SelectContext(ctx, &counts, query, args...); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// recordChange writes the audit entry and price history for a single product change
+func (r *ProductRepository) recordChange(ctx context.Context, tx *sqlx.Tx, action string, before, after *models.Product) error {
+	if err := r.recordHistory(ctx, tx, newHistoryEntry(ctx, action, before, after)); err != nil {
+		return err
+	}
+	return r.recordPriceChange(ctx, tx, before, after)
+}
+
+// lockProduct loads a product row and locks it until the transaction ends
+func (r *ProductRepository) lockProduct(ctx context.Context, tx *sqlx.Tx, id uuid.UUID, includeDeleted bool) (*models.Product, error) {
+	var product models.Product
+	query := `SELECT * FROM products WHERE id = $1 AND ($2 OR deleted_at IS NULL) FOR UPDATE`
+
+	err := tx.GetContext(ctx, &product, query, id, includeDeleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// withTx runs fn inside a transaction, committing on success and rolling back on error
+func (r *ProductRepository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return translateConstraintError(err)
+	}
+
+	return tx.Commit()
+}
+
+// translateConstraintError maps unique violations of known indexes to repository errors
+func translateConstraintError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != uniqueViolation {
+		return err
+	}
+	switch pqErr.Constraint {
+	case skuIndexName:
+		return fmt.Errorf("%w: %s", ErrDuplicateSKU, pqErr.Detail)
+	case variantSKUIndexName:
+		return fmt.Errorf("%w: %s", ErrDuplicateVariantSKU, pqErr.Detail)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// CreateSchedule stores a pending price schedule unless it overlaps another open schedule of the product
+func (r *ProductMemoryRepository) CreateSchedule(ctx context.Context, schedule *models.PriceSchedule) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(schedule.ProductID, false) < 0 {
+		return ErrProductNotFound
+	}
+
+	for i := range r.schedules[schedule.ProductID] {
+		existing := &r.schedules[schedule.ProductID][i]
+		if existing.IsOpen() && schedule.Overlaps(existing) {
+			return ErrScheduleOverlap
+		}
+	}
+
+	r.schedules[schedule.ProductID] = append(r.schedules[schedule.ProductID], *schedule)
+	return nil
+}
+
+// ListSchedules retrieves the price schedules of a product ordered by start time
+func (r *ProductMemoryRepository) ListSchedules(ctx context.Context, productID uuid.UUID) ([]models.PriceSchedule, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.PriceSchedule, len(r.schedules[productID]))
+	copy(result, r.schedules[productID])
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StartsAt.Before(result[j].StartsAt)
+	})
+	return result, nil
+}
+
+// CancelSchedule cancels an open price schedule, reverting the price if it is already active
+func (r *ProductMemoryRepository) CancelSchedule(ctx context.Context, productID, scheduleID uuid.UUID) (*models.PriceSchedule, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.schedules[productID] {
+		schedule := &r.schedules[productID][i]
+		if schedule.ID != scheduleID {
+			continue
+		}
+		if !schedule.IsOpen() {
+			return nil, ErrScheduleClosed
+		}
+
+		now := time.Now()
+		if schedule.Status == models.ScheduleStatusActive {
+			r.revertSchedule(ctx, schedule, now)
+		}
+		schedule.Status = models.ScheduleStatusCancelled
+		schedule.UpdatedAt = now
+
+		scheduleCopy := *schedule
+		return &scheduleCopy, nil
+	}
+	return nil, ErrScheduleNotFound
+}
+
+// ApplyDueSchedules applies and reverts price schedules whose time has come
+func (r *ProductMemoryRepository) ApplyDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	processed := 0
+	for productID := range r.schedules {
+		for i := range r.schedules[productID] {
+			schedule := &r.schedules[productID][i]
+			due := (schedule.Status == models.ScheduleStatusPending && !schedule.StartsAt.After(now)) ||
+				(schedule.Status == models.ScheduleStatusActive && schedule.EndsAt != nil && !schedule.EndsAt.After(now))
+			if !due {
+				continue
+			}
+
+			r.advanceSchedule(ctx, schedule, now)
+			processed++
+		}
+	}
+	return processed, nil
+}
+
+// advanceSchedule moves a due schedule to its next state; callers must hold the write lock
+func (r *ProductMemoryRepository) advanceSchedule(ctx context.Context, schedule *models.PriceSchedule, now time.Time) {
+	schedule.UpdatedAt = now
+
+	if schedule.Status == models.ScheduleStatusActive {
+		r.revertSchedule(ctx, schedule, now)
+		return
+	}
+
+	// The whole window passed while no scheduler was running
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(now) {
+		schedule.Status = models.ScheduleStatusExpired
+		return
+	}
+
+	index := r.findIndex(schedule.ProductID, false)
+	if index < 0 {
+		schedule.Status = models.ScheduleStatusCancelled
+		return
+	}
+
+	previousPrice := r.products[index].Price
+	r.setPrice(ctx, index, schedule.Price, now, models.HistoryActionScheduleApply)
+
+	schedule.PreviousPrice = &previousPrice
+	schedule.AppliedAt = &now
+	schedule.Status = models.ScheduleStatusCompleted
+	if schedule.EndsAt != nil {
+		schedule.Status = models.ScheduleStatusActive
+	}
+}
+
+// revertSchedule restores the price an active schedule replaced; callers must hold the write lock.
+// A price changed by someone else while the schedule was active is left alone.
+func (r *ProductMemoryRepository) revertSchedule(ctx context.Context, schedule *models.PriceSchedule, now time.Time) {
+	schedule.Status = models.ScheduleStatusCompleted
+	schedule.RevertedAt = &now
+
+	index := r.findIndex(schedule.ProductID, false)
+	if index < 0 || schedule.PreviousPrice == nil || r.products[index].Price != schedule.Price {
+		return
+	}
+	r.setPrice(ctx, index, *schedule.PreviousPrice, now, models.HistoryActionScheduleRevert)
+}
+
+// setPrice changes the price of a stored product, recording the change; callers must hold the write lock
+func (r *ProductMemoryRepository) setPrice(ctx context.Context, index int, price float64, now time.Time, action string) {
+	updated := r.products[index]
+	updated.Price = price
+	updated.UpdatedAt = now
+	updated.Version++
+
+	r.storeProduct(ctx, action, index, updated)
+}
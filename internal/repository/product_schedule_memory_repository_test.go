@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// scheduleTestStart is the start of the schedules created by the tests
+var scheduleTestStart = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// createTestSchedule schedules price for the product from scheduleTestStart, lasting duration
+// when it is positive and open-ended otherwise
+func createTestSchedule(t *testing.T, repo *ProductMemoryRepository, product *models.Product, price float64, duration time.Duration) models.PriceSchedule {
+	t.Helper()
+	req := models.PriceScheduleRequest{Price: price, StartsAt: scheduleTestStart}
+	if duration > 0 {
+		endsAt := scheduleTestStart.Add(duration)
+		req.EndsAt = &endsAt
+	}
+	schedule := req.ToPriceSchedule(product.ID)
+	if err := repo.CreateSchedule(context.Background(), &schedule); err != nil {
+		t.Fatal(err)
+	}
+	return schedule
+}
+
+// assertPrice fails unless the stored product has price and version
+func assertPrice(t *testing.T, repo *ProductMemoryRepository, product *models.Product, price float64, version int64) {
+	t.Helper()
+	stored, err := repo.GetByID(context.Background(), product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Price != price || stored.Version != version {
+		t.Fatalf("got price %v at version %d, want %v at version %d", stored.Price, stored.Version, price, version)
+	}
+}
+
+// scheduleStatus returns the stored status of the product's only schedule
+func scheduleStatus(t *testing.T, repo *ProductMemoryRepository, product *models.Product) string {
+	t.Helper()
+	schedules, err := repo.ListSchedules(context.Background(), product.ID)
+	if err != nil || len(schedules) != 1 {
+		t.Fatalf("got schedules %+v, %v", schedules, err)
+	}
+	return schedules[0].Status
+}
+
+func TestMemoryScheduleAppliesAndRevertsOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "SCHEDULE-1")
+	createTestSchedule(t, repo, product, 8, time.Hour)
+
+	steps := []struct {
+		at        time.Time
+		processed int
+		price     float64
+		version   int64
+		status    string
+	}{
+		{scheduleTestStart.Add(-time.Second), 0, 10, 1, models.ScheduleStatusPending},
+		{scheduleTestStart, 1, 8, 2, models.ScheduleStatusActive},
+		{scheduleTestStart.Add(time.Minute), 0, 8, 2, models.ScheduleStatusActive},
+		{scheduleTestStart.Add(time.Hour), 1, 10, 3, models.ScheduleStatusCompleted},
+		{scheduleTestStart.Add(2 * time.Hour), 0, 10, 3, models.ScheduleStatusCompleted},
+	}
+	for _, step := range steps {
+		processed, err := repo.ApplyDueSchedules(ctx, step.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if processed != step.processed {
+			t.Fatalf("at %s: processed %d, want %d", step.at, processed, step.processed)
+		}
+		assertPrice(t, repo, product, step.price, step.version)
+		if status := scheduleStatus(t, repo, product); status != step.status {
+			t.Fatalf("at %s: got status %q, want %q", step.at, status, step.status)
+		}
+	}
+}
+
+func TestMemoryScheduleMissedWindowExpires(t *testing.T) {
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "SCHEDULE-2")
+	createTestSchedule(t, repo, product, 8, time.Hour)
+
+	if _, err := repo.ApplyDueSchedules(context.Background(), scheduleTestStart.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assertPrice(t, repo, product, 10, 1)
+	if status := scheduleStatus(t, repo, product); status != models.ScheduleStatusExpired {
+		t.Fatalf("got status %q, want expired", status)
+	}
+}
+
+func TestMemoryScheduleRevertKeepsManualPriceChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "SCHEDULE-3")
+	createTestSchedule(t, repo, product, 8, time.Hour)
+
+	if _, err := repo.ApplyDueSchedules(ctx, scheduleTestStart); err != nil {
+		t.Fatal(err)
+	}
+	req := &models.ProductRequest{SKU: product.SKU, Name: product.Name, Price: 9}
+	if err := repo.Update(ctx, product.ID, req, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.ApplyDueSchedules(ctx, scheduleTestStart.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	assertPrice(t, repo, product, 9, 3)
+	if status := scheduleStatus(t, repo, product); status != models.ScheduleStatusCompleted {
+		t.Fatalf("got status %q, want completed", status)
+	}
+}
+
+func TestMemoryScheduleRejectsOverlaps(t *testing.T) {
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "SCHEDULE-4")
+	createTestSchedule(t, repo, product, 8, time.Hour)
+
+	endsAt := scheduleTestStart.Add(2 * time.Hour)
+	req := models.PriceScheduleRequest{Price: 7, StartsAt: scheduleTestStart.Add(30 * time.Minute), EndsAt: &endsAt}
+	schedule := req.ToPriceSchedule(product.ID)
+	if err := repo.CreateSchedule(context.Background(), &schedule); !errors.Is(err, ErrScheduleOverlap) {
+		t.Fatalf("got %v, want ErrScheduleOverlap", err)
+	}
+}
+
+func TestMemoryConcurrentSchedulerPassesApplyOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "SCHEDULE-5")
+	createTestSchedule(t, repo, product, 8, 0)
+
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		processed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count, err := repo.ApplyDueSchedules(ctx, scheduleTestStart)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mutex.Lock()
+			processed += count
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if processed != 1 {
+		t.Fatalf("schedule was processed %d times, want once", processed)
+	}
+	assertPrice(t, repo, product, 8, 2)
+}
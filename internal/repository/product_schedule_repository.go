@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+)
+
+const (
+	// maxScheduleAttempts is how often a failing schedule is tried before it is marked failed
+	maxScheduleAttempts = 5
+
+	// scheduleRetryDelay is how long a failing schedule is left alone before it is tried again
+	scheduleRetryDelay = time.Minute
+)
+
+// updateScheduleQuery persists the state of a locked price schedule
+const updateScheduleQuery = `
+	UPDATE product_price_schedules 
+	SET status = :status, 
+		previous_price = :previous_price, 
+		applied_at = :applied_at, 
+		reverted_at = :reverted_at, 
+		updated_at = :updated_at 
+	WHERE id = :id
+`
+
+// CreateSchedule stores a pending price schedule unless it overlaps another open schedule of the product
+func (r *ProductRepository) CreateSchedule(ctx context.Context, schedule *models.PriceSchedule) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Locking the product serialises schedule creation for it
+		if _, err := r.lockProduct(ctx, tx, schedule.ProductID, false); err != nil {
+			return err
+		}
+
+		var open []models.PriceSchedule
+		query := `SELECT * FROM product_price_schedules WHERE product_id = $1 AND status IN ('pending', 'active')`
+		if err := tx.SelectContext(ctx, &open, query, schedule.ProductID); err != nil {
+			return err
+		}
+		for i := range open {
+			if schedule.Overlaps(&open[i]) {
+				return ErrScheduleOverlap
+			}
+		}
+
+		query = `
+			INSERT INTO product_price_schedules 
+			(id, product_id, price, starts_at, ends_at, status, created_at, updated_at) 
+			VALUES (:id, :product_id, :price, :starts_at, :ends_at, :status, :created_at, :updated_at)
+		`
+		_, err := tx.NamedExecContext(ctx, query, schedule)
+		return err
+	})
+}
+
+// ListSchedules retrieves the price schedules of a product ordered by start time
+func (r *ProductRepository) ListSchedules(ctx context.Context, productID uuid.UUID) ([]models.PriceSchedule, error) {
+	var schedules []models.PriceSchedule
+	query := `SELECT * FROM product_price_schedules WHERE product_id = $1 ORDER BY starts_at ASC`
+
+	err := r.db.SelectContext(ctx, &schedules, query, productID)
+	return schedules, err
+}
+
+// CancelSchedule cancels an open price schedule, reverting the price if it is already active
+func (r *ProductRepository) CancelSchedule(ctx context.Context, productID, scheduleID uuid.UUID) (*models.PriceSchedule, error) {
+	var schedule models.PriceSchedule
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `SELECT * FROM product_price_schedules WHERE id = $1 AND product_id = $2 FOR UPDATE`
+		err := tx.GetContext(ctx, &schedule, query, scheduleID, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrScheduleNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !schedule.IsOpen() {
+			return ErrScheduleClosed
+		}
+
+		now := time.Now()
+		if schedule.Status == models.ScheduleStatusActive {
+			if err := r.revertSchedule(ctx, tx, &schedule, now); err != nil {
+				return err
+			}
+		}
+		schedule.Status = models.ScheduleStatusCancelled
+		schedule.UpdatedAt = now
+
+		_, err = tx.NamedExecContext(ctx, updateScheduleQuery, schedule)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// ApplyDueSchedules applies and reverts price schedules whose time has come.
+// Each schedule is claimed with FOR UPDATE SKIP LOCKED and advanced in its own transaction,
+// so replicas running the scheduler concurrently never process the same transition twice.
+// A schedule that fails is retried after scheduleRetryDelay, and marked failed after
+// maxScheduleAttempts, without holding up the schedules due after it.
+func (r *ProductRepository) ApplyDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	advance := func(ctx context.Context) (uuid.UUID, bool, error) {
+		return r.advanceNextSchedule(ctx, now)
+	}
+	fail := func(ctx context.Context, scheduleID uuid.UUID, cause error) error {
+		return r.recordScheduleFailure(ctx, scheduleID, cause, now)
+	}
+	return runDueSchedules(ctx, advance, fail)
+}
+
+// runDueSchedules advances due schedules one at a time until advance claims none. A schedule
+// that fails to advance is handed to fail, which must keep it from being claimed again in this
+// pass, and the pass moves on to the next one; the failures are returned together.
+func runDueSchedules(ctx context.Context, advance func(ctx context.Context) (uuid.UUID, bool, error), fail func(ctx context.Context, scheduleID uuid.UUID, cause error) error) (int, error) {
+	processed := 0
+	var failures []error
+	for {
+		scheduleID, claimed, err := advance(ctx)
+		if !claimed {
+			return processed, errors.Join(append(failures, err)...)
+		}
+		if err == nil {
+			processed++
+			continue
+		}
+
+		if failErr := fail(ctx, scheduleID, err); failErr != nil {
+			return processed, errors.Join(append(failures, err, failErr)...)
+		}
+		failures = append(failures, fmt.Errorf("price schedule %s: %w", scheduleID, err))
+	}
+}
+
+// advanceNextSchedule claims the earliest due schedule and advances it. It reports the ID of
+// the schedule it claimed, if any; the transaction is rolled back when advancing fails.
+func (r *ProductRepository) advanceNextSchedule(ctx context.Context, now time.Time) (uuid.UUID, bool, error) {
+	var schedule models.PriceSchedule
+	claimed := false
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			SELECT * FROM product_price_schedules 
+			WHERE ((status = 'pending' AND starts_at <= $1) 
+				OR (status = 'active' AND ends_at <= $1)) 
+				AND (retry_at IS NULL OR retry_at <= $1) 
+			ORDER BY starts_at ASC 
+			LIMIT 1 
+			FOR UPDATE SKIP LOCKED
+		`
+		err := tx.GetContext(ctx, &schedule, query, now)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		claimed = true
+
+		if err := r.advanceSchedule(ctx, tx, &schedule, now); err != nil {
+			return err
+		}
+		_, err = tx.NamedExecContext(ctx, updateScheduleQuery, schedule)
+		return err
+	})
+	return schedule.ID, claimed, err
+}
+
+// recordScheduleFailure counts a failed attempt at a schedule and puts it aside until
+// scheduleRetryDelay has passed, marking it failed once maxScheduleAttempts are used up
+func (r *ProductRepository) recordScheduleFailure(ctx context.Context, scheduleID uuid.UUID, cause error, now time.Time) error {
+	query := `
+		UPDATE product_price_schedules 
+		SET attempts = attempts + 1, 
+			last_error = $2, 
+			retry_at = $3, 
+			status = CASE WHEN attempts + 1 >= $4 THEN 'failed' ELSE status END, 
+			updated_at = $5 
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, scheduleID, cause.Error(), now.Add(scheduleRetryDelay), maxScheduleAttempts, now)
+	return err
+}
+
+// advanceSchedule moves a due schedule to its next state, changing the product price as needed
+func (r *ProductRepository) advanceSchedule(ctx context.Context, tx *sqlx.Tx, schedule *models.PriceSchedule, now time.Time) error {
+	schedule.UpdatedAt = now
+
+	if schedule.Status == models.ScheduleStatusActive {
+		return r.revertSchedule(ctx, tx, schedule, now)
+	}
+
+	// The whole window passed while no scheduler was running
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(now) {
+		schedule.Status = models.ScheduleStatusExpired
+		return nil
+	}
+
+	product, err := r.lockProduct(ctx, tx, schedule.ProductID, false)
+	if errors.Is(err, ErrProductNotFound) {
+		schedule.Status = models.ScheduleStatusCancelled
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	previousPrice := product.Price
+	if err := r.setPrice(ctx, tx, product, schedule.Price, now, models.HistoryActionScheduleApply); err != nil {
+		return err
+	}
+
+	schedule.PreviousPrice = &previousPrice
+	schedule.AppliedAt = &now
+	schedule.Status = models.ScheduleStatusCompleted
+	if schedule.EndsAt != nil {
+		schedule.Status = models.ScheduleStatusActive
+	}
+	return nil
+}
+
+// revertSchedule restores the price an active schedule replaced.
+// A price changed by someone else while the schedule was active is left alone.
+func (r *ProductRepository) revertSchedule(ctx context.Context, tx *sqlx.Tx, schedule *models.PriceSchedule, now time.Time) error {
+	schedule.Status = models.ScheduleStatusCompleted
+	schedule.RevertedAt = &now
+
+	product, err := r.lockProduct(ctx, tx, schedule.ProductID, false)
+	if errors.Is(err, ErrProductNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if schedule.PreviousPrice == nil || product.Price != schedule.Price {
+		return nil
+	}
+	return r.setPrice(ctx, tx, product, *schedule.PreviousPrice, now, models.HistoryActionScheduleRevert)
+}
+
+// setPrice changes the price of a locked product, recording the change
+func (r *ProductRepository) setPrice(ctx context.Context, tx *sqlx.Tx, before *models.Product, price float64, now time.Time, action string) error {
+	after := *before
+	after.Price = price
+	after.UpdatedAt = now
+	after.Version++
+
+	if _, err := tx.NamedExecContext(ctx, updateProductQuery, after); err != nil {
+		return err
+	}
+	return r.recordChange(ctx, tx, action, before, &after)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeDueSchedule is a due schedule of fakeScheduleQueue
+type fakeDueSchedule struct {
+	id       uuid.UUID
+	err      error
+	advanced bool
+	failures int
+}
+
+// fakeScheduleQueue stands in for the schedule table: advance claims the earliest due schedule
+// not yet advanced or put aside, like the claim query does
+type fakeScheduleQueue struct {
+	schedules []*fakeDueSchedule
+}
+
+func (q *fakeScheduleQueue) advance(ctx context.Context) (uuid.UUID, bool, error) {
+	for _, schedule := range q.schedules {
+		if schedule.advanced || schedule.failures > 0 {
+			continue
+		}
+		if schedule.err == nil {
+			schedule.advanced = true
+		}
+		return schedule.id, true, schedule.err
+	}
+	return uuid.Nil, false, nil
+}
+
+func (q *fakeScheduleQueue) fail(ctx context.Context, scheduleID uuid.UUID, cause error) error {
+	for _, schedule := range q.schedules {
+		if schedule.id == scheduleID {
+			schedule.failures++
+		}
+	}
+	return nil
+}
+
+func TestRunDueSchedulesContinuesPastFailingSchedule(t *testing.T) {
+	errPrice := errors.New("price update failed")
+	failing := &fakeDueSchedule{id: uuid.New(), err: errPrice}
+	healthy := &fakeDueSchedule{id: uuid.New()}
+	queue := &fakeScheduleQueue{schedules: []*fakeDueSchedule{failing, healthy}}
+
+	processed, err := runDueSchedules(context.Background(), queue.advance, queue.fail)
+	if processed != 1 || !healthy.advanced {
+		t.Fatalf("processed %d, healthy advanced %v; want the healthy schedule applied", processed, healthy.advanced)
+	}
+	if failing.failures != 1 {
+		t.Fatalf("failure recorded %d times, want once", failing.failures)
+	}
+	if !errors.Is(err, errPrice) {
+		t.Fatalf("got %v, want the failure reported", err)
+	}
+}
+
+func TestRunDueSchedulesStopsWhenFailureCannotBeRecorded(t *testing.T) {
+	errPrice := errors.New("price update failed")
+	errStore := errors.New("database unavailable")
+	claims := 0
+	advance := func(ctx context.Context) (uuid.UUID, bool, error) {
+		claims++
+		return uuid.New(), true, errPrice
+	}
+	fail := func(ctx context.Context, scheduleID uuid.UUID, cause error) error {
+		return errStore
+	}
+
+	processed, err := runDueSchedules(context.Background(), advance, fail)
+	if processed != 0 || claims != 1 {
+		t.Fatalf("processed %d after %d claims, want to stop after the first", processed, claims)
+	}
+	if !errors.Is(err, errPrice) || !errors.Is(err, errStore) {
+		t.Fatalf("got %v, want both errors reported", err)
+	}
+}
+
+func TestRunDueSchedulesReportsClaimErrors(t *testing.T) {
+	errClaim := errors.New("claim failed")
+	advance := func(ctx context.Context) (uuid.UUID, bool, error) {
+		return uuid.Nil, false, errClaim
+	}
+	fail := func(ctx context.Context, scheduleID uuid.UUID, cause error) error {
+		t.Fatal("no schedule was claimed")
+		return nil
+	}
+
+	if processed, err := runDueSchedules(context.Background(), advance, fail); processed != 0 || !errors.Is(err, errClaim) {
+		t.Fatalf("got %d, %v", processed, err)
+	}
+}
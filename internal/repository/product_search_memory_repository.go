@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+	"product-service/pkg/search"
+)
+
+// Search finds the products matching a web-search style query, most relevant first, and
+// returns one page of them with the total number of matches. Matching and highlighting
+// cover the default-locale name and description.
+func (r *ProductMemoryRepository) Search(ctx context.Context, text string, page, pageSize int, filter models.ProductFilter) ([]models.SearchHit, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	query := search.ParseQuery(text)
+	matches := r.productMatcher(filter)
+
+	scores := make(map[uuid.UUID]float64)
+	for _, match := range r.searchIndex.Search(query) {
+		scores[uuid.MustParse(match.ID)] = match.Score
+	}
+
+	hits := []models.SearchHit{}
+	for i := range r.products {
+		score, ok := scores[r.products[i].ID]
+		if ok && matches(&r.products[i]) {
+			hits = append(hits, models.SearchHit{Product: r.products[i], Score: score})
+		}
+	}
+
+	// Break ties by recency like the database does
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Product.CreatedAt.After(hits[j].Product.CreatedAt)
+	})
+
+	total := len(hits)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	hits = hits[start:end]
+
+	products := make([]models.Product, len(hits))
+	for i := range hits {
+		products[i] = hits[i].Product
+	}
+	if filter.AsOf != nil {
+		r.applyPricesAsOf(products, *filter.AsOf)
+	}
+	if filter.Include.Any() {
+		r.includeRelated(products, filter.Include)
+	}
+
+	for i := range hits {
+		hits[i].Product = products[i]
+		hits[i].Highlights = models.SearchHighlights{
+			Name:        search.Highlight(products[i].Name, query, 0),
+			Description: search.Highlight(products[i].Description, query, models.SearchDescriptionWords),
+		}
+	}
+	return hits, total, nil
+}
+
+// indexProduct keeps the search and suggestion indexes in step with a stored product; callers
+// must hold the lock
+func (r *ProductMemoryRepository) indexProduct(product *models.Product) {
+	id := product.ID.String()
+	r.searchIndex.Add(id,
+		search.Field{Text: product.Name, Weight: search.WeightTitle},
+		search.Field{Text: product.Description, Weight: search.WeightBody},
+	)
+	r.suggestIndex.Add(id, product.Name)
+}
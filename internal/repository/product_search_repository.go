@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"product-service/internal/models"
+)
+
+// productSearchDocument is the weighted text searched for each product: the name under
+// label A and the description under label B. The GIN index idx_product_search is built on
+// this exact expression, so the two must change together.
+const productSearchDocument = `(setweight(to_tsvector('english', name), 'A') || ` +
+	`setweight(to_tsvector('english', coalesce(description, '')), 'B'))`
+
+// productSearchHeadline configures ts_headline for the description; the name is always
+// highlighted whole
+var productSearchHeadline = fmt.Sprintf(
+	"StartSel=<mark>, StopSel=</mark>, MaxWords=%d, MinWords=15", models.SearchDescriptionWords)
+
+// escapeHTMLSQL wraps a text expression in the SQL that escapes it like html.EscapeString.
+// Headlines are built from escaped text, so that their markers are their only markup.
+func escapeHTMLSQL(expr string) string {
+	return fmt.Sprintf(`replace(replace(replace(replace(replace(%s,
+		'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'), '''', '&#39;')`, expr)
+}
+
+// searchRow is a product row with the rank and highlights of a search
+type searchRow struct {
+	models.Product
+	Rank                 float64 `db:"rank"`
+	NameHighlight        string  `db:"name_highlight"`
+	DescriptionHighlight string  `db:"description_highlight"`
+}
+
+// Search finds the products matching a web-search style query, most relevant first, and
+// returns one page of them with the total number of matches. Matching and highlighting
+// cover the default-locale name and description.
+func (r *ProductRepository) Search(ctx context.Context, text string, page, pageSize int, filter models.ProductFilter) ([]models.SearchHit, int, error) {
+	where, args := buildProductFilter(filter)
+	args = append(args, text)
+	match := fmt.Sprintf("%s @@ websearch_to_tsquery('english', $%d)", productSearchDocument, len(args))
+	if where == "" {
+		where = "WHERE " + match
+	} else {
+		where += " AND " + match
+	}
+
+	var total int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM products %s`, where)
+	if err := r.db.GetContext(ctx, &total, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	// Headlines are costly, so they are only built for the rows of the page
+	queryArg := len(args)
+	args = append(args, pageSize, (page-1)*pageSize, productSearchHeadline)
+	query = fmt.Sprintf(`
+		SELECT hits.*,
+			ts_headline('english', %[7]s, websearch_to_tsquery('english', $%[1]d),
+				'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS name_highlight,
+			ts_headline('english', %[8]s, websearch_to_tsquery('english', $%[1]d),
+				$%[4]d) AS description_highlight
+		FROM (
+			SELECT *, ts_rank(%[5]s, websearch_to_tsquery('english', $%[1]d)) AS rank
+			FROM products
+			%[6]s
+			ORDER BY rank DESC, created_at DESC
+			LIMIT $%[2]d OFFSET $%[3]d
+		) hits
+		ORDER BY hits.rank DESC, hits.created_at DESC
+	`, queryArg, queryArg+1, queryArg+2, queryArg+3, productSearchDocument, where,
+		escapeHTMLSQL("hits.name"), escapeHTMLSQL("coalesce(hits.description, '')"))
+
+	var rows []searchRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	products := make([]models.Product, len(rows))
+	for i := range rows {
+		products[i] = rows[i].Product
+	}
+	if filter.AsOf != nil {
+		if err := r.ApplyPricesAsOf(ctx, products, *filter.AsOf); err != nil {
+			return nil, 0, err
+		}
+	}
+	if filter.Include.Any() {
+		if err := r.includeRelated(ctx, products, filter.Include); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	hits := make([]models.SearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = models.SearchHit{
+			Product: products[i],
+			Score:   row.Rank,
+			Highlights: models.SearchHighlights{
+				Name:        row.NameHighlight,
+				Description: row.DescriptionHighlight,
+			},
+		}
+	}
+	return hits, total, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// Transition moves a live product to another lifecycle state and bumps its version.
+// A non-zero expectedVersion makes the write conditional on the stored version.
+func (r *ProductMemoryRepository) Transition(ctx context.Context, id uuid.UUID, status string, expectedVersion int64) (*models.Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	index := r.findIndex(id, false)
+	if index < 0 {
+		return nil, ErrProductNotFound
+	}
+	if expectedVersion != 0 && r.products[index].Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+	if err := checkTransition(r.products[index].Status, status); err != nil {
+		return nil, err
+	}
+
+	updated := r.products[index]
+	updated.Status = status
+	updated.UpdatedAt = time.Now()
+	updated.Version++
+
+	r.storeProduct(ctx, models.HistoryActionTransition, index, updated)
+	return &updated, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+)
+
+// Transition moves a live product to another lifecycle state and bumps its version.
+// A non-zero expectedVersion makes the write conditional on the stored version.
+func (r *ProductRepository) Transition(ctx context.Context, id uuid.UUID, status string, expectedVersion int64) (*models.Product, error) {
+	var after models.Product
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		before, err := r.lockProduct(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != 0 && before.Version != expectedVersion {
+			return ErrVersionMismatch
+		}
+		if err := checkTransition(before.Status, status); err != nil {
+			return err
+		}
+
+		after = *before
+		after.Status = status
+		after.UpdatedAt = time.Now()
+		after.Version++
+
+		if _, err := tx.NamedExecContext(ctx, updateProductQuery, after); err != nil {
+			return err
+		}
+		return r.recordChange(ctx, tx, models.HistoryActionTransition, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// checkTransition returns ErrInvalidTransition, naming the allowed targets, when a product
+// may not move between the two states
+func checkTransition(from, to string) error {
+	if models.CanTransition(from, to) {
+		return nil
+	}
+	return fmt.Errorf("%w: a %s product cannot become %s; allowed: %v",
+		ErrInvalidTransition, from, to, models.AllowedTransitions(from))
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// stockKey identifies the stock level of a product or one of its variants
+type stockKey struct {
+	productID uuid.UUID
+	variantID uuid.UUID
+}
+
+// AdjustStock adds a signed quantity to the on-hand stock. It refuses to leave less stock
+// on hand than is reserved.
+func (r *ProductMemoryRepository) AdjustStock(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	return r.moveStock(ctx, models.StockMovementAdjust, productID, variantID, quantity, reason)
+}
+
+// ReserveStock sets aside a quantity of the available stock
+func (r *ProductMemoryRepository) ReserveStock(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	return r.moveStock(ctx, models.StockMovementReserve, productID, variantID, quantity, reason)
+}
+
+// ReleaseStock returns a reserved quantity to the available stock
+func (r *ProductMemoryRepository) ReleaseStock(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	return r.moveStock(ctx, models.StockMovementRelease, productID, variantID, quantity, reason)
+}
+
+// moveStock applies a movement to a stock level and records it in the ledger.
+// The write lock makes the check and the update a single step.
+func (r *ProductMemoryRepository) moveStock(ctx context.Context, kind string, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	if variantID != uuid.Nil && r.findVariantIndex(productID, variantID) < 0 {
+		return nil, ErrVariantNotFound
+	}
+
+	key := stockKey{productID: productID, variantID: variantID}
+	level, ok := r.stock[key]
+	if !ok {
+		level = models.StockLevel{ProductID: productID, VariantID: variantID}
+	}
+
+	switch kind {
+	case models.StockMovementAdjust:
+		if level.OnHand+quantity < level.Reserved {
+			return nil, ErrInsufficientStock
+		}
+		level.OnHand += quantity
+	case models.StockMovementReserve:
+		if level.Available() < quantity {
+			return nil, ErrInsufficientStock
+		}
+		level.Reserved += quantity
+	case models.StockMovementRelease:
+		if level.Reserved < quantity {
+			return nil, ErrInsufficientReserved
+		}
+		level.Reserved -= quantity
+	}
+	level.UpdatedAt = time.Now()
+	r.stock[key] = level
+
+	r.movementSeq++
+	movement := newStockMovement(ctx, kind, &level, quantity, reason)
+	movement.ID = r.movementSeq
+	r.movements[productID] = append(r.movements[productID], movement)
+
+	return &level, nil
+}
+
+// GetStock retrieves the stock levels of a live product and its variants
+func (r *ProductMemoryRepository) GetStock(ctx context.Context, productID uuid.UUID) ([]models.StockLevel, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+
+	levels := []models.StockLevel{}
+	for key, level := range r.stock {
+		if key.productID == productID {
+			levels = append(levels, level)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].VariantID.String() < levels[j].VariantID.String()
+	})
+	return levels, nil
+}
+
+// GetStockMovements retrieves the stock ledger of a product, oldest first
+func (r *ProductMemoryRepository) GetStockMovements(ctx context.Context, productID uuid.UUID) ([]models.StockMovement, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make([]models.StockMovement, len(r.movements[productID]))
+	copy(result, r.movements[productID])
+	return result, nil
+}
+
+// removeStock drops the stock levels of a purged product; callers must hold the write lock
+func (r *ProductMemoryRepository) removeStock(productID uuid.UUID) {
+	for key := range r.stock {
+		if key.productID == productID {
+			delete(r.stock, key)
+		}
+	}
+}
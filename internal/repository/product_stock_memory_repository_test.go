@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+	"product-service/pkg/storage"
+)
+
+// newTestMemoryRepository creates an empty in-memory repository
+func newTestMemoryRepository() *ProductMemoryRepository {
+	return NewProductMemoryRepository(storage.NewMemoryStore())
+}
+
+// createTestProduct stores a live product with the given SKU
+func createTestProduct(t *testing.T, repo *ProductMemoryRepository, sku string) *models.Product {
+	t.Helper()
+	req := models.ProductRequest{SKU: sku, Name: "Product " + sku, Price: 10}
+	product := req.ToProduct()
+	if err := repo.Create(context.Background(), &product); err != nil {
+		t.Fatalf("create %s: %v", sku, err)
+	}
+	return &product
+}
+
+// assertStock fails unless the product-level stock holds onHand and reserved
+func assertStock(t *testing.T, repo *ProductMemoryRepository, productID uuid.UUID, onHand, reserved int64) {
+	t.Helper()
+	levels, err := repo.GetStock(context.Background(), productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 1 || levels[0].OnHand != onHand || levels[0].Reserved != reserved {
+		t.Fatalf("got stock %+v, want on hand %d and reserved %d", levels, onHand, reserved)
+	}
+}
+
+func TestMemoryReserveStockPastAvailable(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "STOCK-1")
+
+	if _, err := repo.AdjustStock(ctx, product.ID, uuid.Nil, 5, "delivery"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReserveStock(ctx, product.ID, uuid.Nil, 3, "order 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReserveStock(ctx, product.ID, uuid.Nil, 3, "order 2"); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("got %v, want ErrInsufficientStock", err)
+	}
+	assertStock(t, repo, product.ID, 5, 3)
+
+	// Stock on hand cannot drop below what is reserved
+	if _, err := repo.AdjustStock(ctx, product.ID, uuid.Nil, -3, "shrinkage"); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("got %v, want ErrInsufficientStock", err)
+	}
+	assertStock(t, repo, product.ID, 5, 3)
+
+	movements, err := repo.GetStockMovements(ctx, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movements) != 2 {
+		t.Fatalf("got %d movements, want only the 2 that succeeded", len(movements))
+	}
+}
+
+func TestMemoryReleaseStockPastReserved(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "STOCK-2")
+
+	if _, err := repo.AdjustStock(ctx, product.ID, uuid.Nil, 5, "delivery"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReserveStock(ctx, product.ID, uuid.Nil, 2, "order 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ReleaseStock(ctx, product.ID, uuid.Nil, 3, "cancel order 1"); !errors.Is(err, ErrInsufficientReserved) {
+		t.Fatalf("got %v, want ErrInsufficientReserved", err)
+	}
+	assertStock(t, repo, product.ID, 5, 2)
+
+	level, err := repo.ReleaseStock(ctx, product.ID, uuid.Nil, 2, "cancel order 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if level.Reserved != 0 || level.Available() != 5 {
+		t.Fatalf("got %+v after releasing everything", level)
+	}
+}
+
+func TestMemoryStockOfUnknownProductOrVariant(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "STOCK-3")
+
+	if _, err := repo.AdjustStock(ctx, uuid.New(), uuid.Nil, 1, ""); !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("got %v, want ErrProductNotFound", err)
+	}
+	if _, err := repo.AdjustStock(ctx, product.ID, uuid.New(), 1, ""); !errors.Is(err, ErrVariantNotFound) {
+		t.Fatalf("got %v, want ErrVariantNotFound", err)
+	}
+}
+
+func TestMemoryConcurrentReservationsNeverOversell(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepository()
+	product := createTestProduct(t, repo, "STOCK-4")
+	if _, err := repo.AdjustStock(ctx, product.ID, uuid.Nil, 50, "delivery"); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		reserved int
+	)
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ReserveStock(ctx, product.ID, uuid.Nil, 1, "order")
+			if err == nil {
+				mutex.Lock()
+				reserved++
+				mutex.Unlock()
+			} else if !errors.Is(err, ErrInsufficientStock) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 50 {
+		t.Fatalf("%d reservations succeeded, want 50", reserved)
+	}
+	assertStock(t, repo, product.ID, 50, 50)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+	"product-service/pkg/requestctx"
+)
+
+// stockUpdates holds the conditional update of each movement kind. The WHERE clause is
+// re-evaluated once a concurrent writer releases the row, so a movement that would overdraw
+// the stock matches no row instead of going negative.
+var stockUpdates = map[string]string{
+	models.StockMovementAdjust: `
+		UPDATE stock_levels
+		SET on_hand = on_hand + $3, updated_at = $4
+		WHERE product_id = $1 AND variant_id = $2 AND on_hand + $3 >= reserved
+		RETURNING *
+	`,
+	models.StockMovementReserve: `
+		UPDATE stock_levels
+		SET reserved = reserved + $3, updated_at = $4
+		WHERE product_id = $1 AND variant_id = $2 AND on_hand - reserved >= $3
+		RETURNING *
+	`,
+	models.StockMovementRelease: `
+		UPDATE stock_levels
+		SET reserved = reserved - $3, updated_at = $4
+		WHERE product_id = $1 AND variant_id = $2 AND reserved >= $3
+		RETURNING *
+	`,
+}
+
+// AdjustStock adds a signed quantity to the on-hand stock. It refuses to leave less stock
+// on hand than is reserved.
+func (r *ProductRepository) AdjustStock(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	return r.moveStock(ctx, models.StockMovementAdjust, productID, variantID, quantity, reason)
+}
+
+// ReserveStock sets aside a quantity of the available stock
+func (r *ProductRepository) ReserveStock(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	return r.moveStock(ctx, models.StockMovementReserve, productID, variantID, quantity, reason)
+}
+
+// ReleaseStock returns a reserved quantity to the available stock
+func (r *ProductRepository) ReleaseStock(ctx context.Context, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	return r.moveStock(ctx, models.StockMovementRelease, productID, variantID, quantity, reason)
+}
+
+// moveStock applies a movement to a stock level and records it in the ledger
+func (r *ProductRepository) moveStock(ctx context.Context, kind string, productID, variantID uuid.UUID, quantity int64, reason string) (*models.StockLevel, error) {
+	var level models.StockLevel
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := r.checkStockTarget(ctx, tx, productID, variantID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		query := `
+			INSERT INTO stock_levels (product_id, variant_id, updated_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (product_id, variant_id) DO NOTHING
+		`
+		if _, err := tx.ExecContext(ctx, query, productID, variantID, now); err != nil {
+			return err
+		}
+
+		err := tx.GetContext(ctx, &level, stockUpdates[kind], productID, variantID, quantity, now)
+		if errors.Is(err, sql.ErrNoRows) {
+			if kind == models.StockMovementRelease {
+				return ErrInsufficientReserved
+			}
+			return ErrInsufficientStock
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO stock_movements
+			(product_id, variant_id, kind, quantity, on_hand, reserved, reason, actor, request_id, created_at)
+			VALUES (:product_id, :variant_id, :kind, :quantity, :on_hand, :reserved, :reason, :actor, :request_id, :created_at)
+		`
+		_, err = tx.NamedExecContext(ctx, query, newStockMovement(ctx, kind, &level, quantity, reason))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &level, nil
+}
+
+// GetStock retrieves the stock levels of a live product and its variants
+func (r *ProductRepository) GetStock(ctx context.Context, productID uuid.UUID) ([]models.StockLevel, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	levels := []models.StockLevel{}
+	query := `SELECT * FROM stock_levels WHERE product_id = $1 ORDER BY variant_id ASC`
+
+	if err := r.db.SelectContext(ctx, &levels, query, productID); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// GetStockMovements retrieves the stock ledger of a product, oldest first
+func (r *ProductRepository) GetStockMovements(ctx context.Context, productID uuid.UUID) ([]models.StockMovement, error) {
+	movements := []models.StockMovement{}
+	query := `SELECT * FROM stock_movements WHERE product_id = $1 ORDER BY id ASC`
+
+	if err := r.db.SelectContext(ctx, &movements, query, productID); err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// checkStockTarget verifies that the product is live and owns the variant, and keeps both
+// from being deleted until the transaction ends
+func (r *ProductRepository) checkStockTarget(ctx context.Context, tx *sqlx.Tx, productID, variantID uuid.UUID) error {
+	var locked uuid.UUID
+	query := `SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR SHARE`
+	err := tx.GetContext(ctx, &locked, query, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil || variantID == uuid.Nil {
+		return err
+	}
+
+	query = `SELECT id FROM product_variants WHERE id = $1 AND product_id = $2 FOR SHARE`
+	err = tx.GetContext(ctx, &locked, query, variantID, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVariantNotFound
+	}
+	return err
+}
+
+// newStockMovement builds the ledger entry for a movement that produced the given level
+func newStockMovement(ctx context.Context, kind string, level *models.StockLevel, quantity int64, reason string) models.StockMovement {
+	return models.StockMovement{
+		ProductID: level.ProductID,
+		VariantID: level.VariantID,
+		Kind:      kind,
+		Quantity:  quantity,
+		OnHand:    level.OnHand,
+		Reserved:  level.Reserved,
+		Reason:    reason,
+		Actor:     requestctx.Actor(ctx),
+		RequestID: requestctx.RequestID(ctx),
+		CreatedAt: level.UpdatedAt,
+	}
+}
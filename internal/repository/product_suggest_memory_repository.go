@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// Suggest returns up to limit products whose name starts with the prefix, has a word starting
+// with it, or is similar to it despite typos, best first
+func (r *ProductMemoryRepository) Suggest(ctx context.Context, prefix string, limit int, filter models.ProductFilter) ([]models.Suggestion, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	candidates := r.suggestIndex.Suggest(prefix)
+	if len(candidates) == 0 {
+		return []models.Suggestion{}, nil
+	}
+
+	products := make(map[uuid.UUID]*models.Product, len(r.products))
+	for i := range r.products {
+		products[r.products[i].ID] = &r.products[i]
+	}
+
+	matches := r.productMatcher(filter)
+	suggestions := []models.Suggestion{}
+	for _, candidate := range candidates {
+		product := products[uuid.MustParse(candidate.ID)]
+		if product == nil || !matches(product) {
+			continue
+		}
+		suggestions = append(suggestions, models.Suggestion{ID: product.ID, Name: product.Name, Score: candidate.Score})
+		if len(suggestions) == limit {
+			break
+		}
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"product-service/internal/models"
+	"product-service/pkg/search"
+)
+
+// productSuggestName is the product name in the form prefixes are compared in: lowercase
+// words of letters and digits separated by single spaces, as search.NormalizeName produces.
+// The trigram index idx_product_name_trgm is built on this exact expression.
+const productSuggestName = `btrim(regexp_replace(lower(name), '[^[:alnum:]]+', ' ', 'g'))`
+
+// Suggest returns up to limit products whose name starts with the prefix, has a word starting
+// with it, or is similar to it despite typos, best first
+func (r *ProductRepository) Suggest(ctx context.Context, prefix string, limit int, filter models.ProductFilter) ([]models.Suggestion, error) {
+	prefix = search.NormalizeName(prefix)
+	if prefix == "" {
+		return []models.Suggestion{}, nil
+	}
+
+	where, args := buildProductFilter(filter)
+	args = append(args, prefix)
+	p := len(args)
+
+	// The normalized prefix holds only letters, digits and spaces, so it needs no LIKE escaping.
+	// <% applies pg_trgm.word_similarity_threshold, 0.6 unless configured otherwise.
+	match := fmt.Sprintf(`(%[1]s LIKE $%[2]d || '%%' OR %[1]s LIKE '%% ' || $%[2]d || '%%' OR $%[2]d <%% %[1]s)`,
+		productSuggestName, p)
+	if where == "" {
+		where = "WHERE " + match
+	} else {
+		where += " AND " + match
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT id, name, word_similarity($%[2]d, %[1]s) AS score
+		FROM products
+		%[3]s
+		ORDER BY
+			CASE WHEN %[1]s LIKE $%[2]d || '%%' THEN 0 WHEN %[1]s LIKE '%% ' || $%[2]d || '%%' THEN 1 ELSE 2 END,
+			score DESC, %[1]s, id
+		LIMIT $%[4]d
+	`, productSuggestName, p, where, p+1)
+
+	suggestions := []models.Suggestion{}
+	if err := r.db.SelectContext(ctx, &suggestions, query, args...); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// PutTranslation creates or replaces the translation of a live product in its locale.
+// It reports whether the translation was created.
+func (r *ProductMemoryRepository) PutTranslation(ctx context.Context, translation *models.ProductTranslation) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(translation.ProductID, false) < 0 {
+		return false, ErrProductNotFound
+	}
+
+	translations := r.translations[translation.ProductID]
+	if translations == nil {
+		translations = make(map[string]models.ProductTranslation)
+		r.translations[translation.ProductID] = translations
+	}
+
+	existing, ok := translations[translation.Locale]
+	if ok {
+		translation.CreatedAt = existing.CreatedAt
+		translation.UpdatedAt = time.Now()
+	}
+	translations[translation.Locale] = *translation
+	return !ok, nil
+}
+
+// ListTranslations retrieves the translations of a live product ordered by locale
+func (r *ProductMemoryRepository) ListTranslations(ctx context.Context, productID uuid.UUID) ([]models.ProductTranslation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+
+	translations := make([]models.ProductTranslation, 0, len(r.translations[productID]))
+	for _, translation := range r.translations[productID] {
+		translations = append(translations, translation)
+	}
+	sort.Slice(translations, func(i, j int) bool {
+		return translations[i].Locale < translations[j].Locale
+	})
+	return translations, nil
+}
+
+// GetTranslation retrieves the translation of a live product in one locale
+func (r *ProductMemoryRepository) GetTranslation(ctx context.Context, productID uuid.UUID, locale string) (*models.ProductTranslation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+
+	translation, ok := r.translations[productID][locale]
+	if !ok {
+		return nil, ErrTranslationNotFound
+	}
+	return &translation, nil
+}
+
+// DeleteTranslation removes the translation of a live product in one locale
+func (r *ProductMemoryRepository) DeleteTranslation(ctx context.Context, productID uuid.UUID, locale string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return ErrProductNotFound
+	}
+	if _, ok := r.translations[productID][locale]; !ok {
+		return ErrTranslationNotFound
+	}
+
+	delete(r.translations[productID], locale)
+	return nil
+}
+
+// ApplyTranslations serves each product in the first locale of the fallback chain it has
+// a translation for
+func (r *ProductMemoryRepository) ApplyTranslations(ctx context.Context, products []models.Product, chain []string) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	r.applyTranslations(products, chain)
+	return nil
+}
+
+// applyTranslations localizes product names and descriptions; callers must hold the lock
+func (r *ProductMemoryRepository) applyTranslations(products []models.Product, chain []string) {
+	var translations []models.ProductTranslation
+	for i := range products {
+		for _, translation := range r.translations[products[i].ID] {
+			translations = append(translations, translation)
+		}
+	}
+	models.LocalizeProducts(products, translations, chain)
+}
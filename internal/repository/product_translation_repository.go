@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"product-service/internal/models"
+)
+
+// PutTranslation creates or replaces the translation of a live product in its locale.
+// It reports whether the translation was created.
+func (r *ProductRepository) PutTranslation(ctx context.Context, translation *models.ProductTranslation) (bool, error) {
+	var created bool
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Keep the product from being deleted until the translation is stored
+		var locked uuid.UUID
+		query := `SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR SHARE`
+		err := tx.GetContext(ctx, &locked, query, translation.ProductID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProductNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// xmax is zero only for rows the statement inserted
+		query = `
+			INSERT INTO product_translations (product_id, locale, name, description, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+			ON CONFLICT (product_id, locale) DO UPDATE
+			SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+			RETURNING created_at, updated_at, xmax = 0
+		`
+		return tx.QueryRowxContext(ctx, query,
+			translation.ProductID, translation.Locale, translation.Name, translation.Description, translation.UpdatedAt,
+		).Scan(&translation.CreatedAt, &translation.UpdatedAt, &created)
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// ListTranslations retrieves the translations of a live product ordered by locale
+func (r *ProductRepository) ListTranslations(ctx context.Context, productID uuid.UUID) ([]models.ProductTranslation, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	translations := []models.ProductTranslation{}
+	query := `SELECT * FROM product_translations WHERE product_id = $1 ORDER BY locale ASC`
+
+	if err := r.db.SelectContext(ctx, &translations, query, productID); err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
+
+// GetTranslation retrieves the translation of a live product in one locale
+func (r *ProductRepository) GetTranslation(ctx context.Context, productID uuid.UUID, locale string) (*models.ProductTranslation, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	var translation models.ProductTranslation
+	query := `SELECT * FROM product_translations WHERE product_id = $1 AND locale = $2`
+
+	err := r.db.GetContext(ctx, &translation, query, productID, locale)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTranslationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &translation, nil
+}
+
+// DeleteTranslation removes the translation of a live product in one locale
+func (r *ProductRepository) DeleteTranslation(ctx context.Context, productID uuid.UUID, locale string) error {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM product_translations WHERE product_id = $1 AND locale = $2`
+	result, err := r.db.ExecContext(ctx, query, productID, locale)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTranslationNotFound
+	}
+	return nil
+}
+
+// ApplyTranslations serves each product in the first locale of the fallback chain it has
+// a translation for
+func (r *ProductRepository) ApplyTranslations(ctx context.Context, products []models.Product, chain []string) error {
+	if len(products) == 0 || len(chain) < 2 {
+		models.LocalizeProducts(products, nil, chain)
+		return nil
+	}
+
+	ids := make(models.UUIDArray, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+
+	var translations []models.ProductTranslation
+	query := `SELECT * FROM product_translations WHERE product_id = ANY($1::uuid[]) AND locale = ANY($2)`
+	if err := r.db.SelectContext(ctx, &translations, query, ids, pq.StringArray(chain[:len(chain)-1])); err != nil {
+		return err
+	}
+
+	models.LocalizeProducts(products, translations, chain)
+	return nil
+}
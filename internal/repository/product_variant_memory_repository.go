@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/models"
+)
+
+// CreateVariant adds a variant to a live product
+func (r *ProductMemoryRepository) CreateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(variant.ProductID, false) < 0 {
+		return ErrProductNotFound
+	}
+	if err := r.checkVariantSKU(variant.SKU, variant.ID); err != nil {
+		return err
+	}
+
+	r.variants[variant.ProductID] = append(r.variants[variant.ProductID], *variant)
+	r.variantSKUs[variant.SKU] = variant.ID
+	return nil
+}
+
+// ListVariants retrieves the variants of a live product in creation order
+func (r *ProductMemoryRepository) ListVariants(ctx context.Context, productID uuid.UUID) ([]models.ProductVariant, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+
+	result := make([]models.ProductVariant, len(r.variants[productID]))
+	copy(result, r.variants[productID])
+	return result, nil
+}
+
+// GetVariant retrieves a single variant of a live product
+func (r *ProductMemoryRepository) GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*models.ProductVariant, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrVariantNotFound
+	}
+	index := r.findVariantIndex(productID, variantID)
+	if index < 0 {
+		return nil, ErrVariantNotFound
+	}
+
+	variant := r.variants[productID][index]
+	return &variant, nil
+}
+
+// UpdateVariant replaces the editable fields of a variant
+func (r *ProductMemoryRepository) UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, req *models.VariantRequest) (*models.ProductVariant, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return nil, ErrProductNotFound
+	}
+	index := r.findVariantIndex(productID, variantID)
+	if index < 0 {
+		return nil, ErrVariantNotFound
+	}
+
+	variant := r.variants[productID][index]
+	if err := r.checkVariantSKU(req.SKU, variantID); err != nil {
+		return nil, err
+	}
+	delete(r.variantSKUs, variant.SKU)
+
+	req.ApplyTo(&variant)
+	variant.UpdatedAt = time.Now()
+
+	r.variants[productID][index] = variant
+	r.variantSKUs[variant.SKU] = variant.ID
+	return &variant, nil
+}
+
+// DeleteVariant permanently removes a variant of a live product together with its stock level
+func (r *ProductMemoryRepository) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.findIndex(productID, false) < 0 {
+		return ErrProductNotFound
+	}
+	index := r.findVariantIndex(productID, variantID)
+	if index < 0 {
+		return ErrVariantNotFound
+	}
+
+	variants := r.variants[productID]
+	delete(r.variantSKUs, variants[index].SKU)
+	r.variants[productID] = append(variants[:index], variants[index+1:]...)
+
+	// The ledger keeps the variant's movements; only its current level goes
+	delete(r.stock, stockKey{productID: productID, variantID: variantID})
+	return nil
+}
+
+// includeVariants embeds the requested variant data into the products; callers must hold the lock
+func (r *ProductMemoryRepository) includeVariants(products []models.Product, include models.ProductIncludes) {
+	var variants []models.ProductVariant
+	for i := range products {
+		variants = append(variants, r.variants[products[i].ID]...)
+	}
+	models.AttachVariants(products, variants, include)
+}
+
+// removeVariants drops all variants of a purged product; callers must hold the write lock
+func (r *ProductMemoryRepository) removeVariants(productID uuid.UUID) {
+	for _, variant := range r.variants[productID] {
+		delete(r.variantSKUs, variant.SKU)
+	}
+	delete(r.variants, productID)
+}
+
+// findVariantIndex returns the slot of a variant within its product, or -1; callers must hold the lock
+func (r *ProductMemoryRepository) findVariantIndex(productID, variantID uuid.UUID) int {
+	for i := range r.variants[productID] {
+		if r.variants[productID][i].ID == variantID {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkVariantSKU returns ErrDuplicateVariantSKU when another variant holds the SKU; callers must hold the lock
+func (r *ProductMemoryRepository) checkVariantSKU(sku string, id uuid.UUID) error {
+	if owner, ok := r.variantSKUs[sku]; ok && owner != id {
+		return fmt.Errorf("%w: %s", ErrDuplicateVariantSKU, sku)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"product-service/internal/models"
+)
+
+// CreateVariant adds a variant to a live product
+func (r *ProductRepository) CreateVariant(ctx context.Context, variant *models.ProductVariant) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Locking the product keeps it from being deleted while the variant is added
+		if _, err := r.lockProduct(ctx, tx, variant.ProductID, false); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO product_variants
+			(id, product_id, sku, name, price, attributes, created_at, updated_at)
+			VALUES (:id, :product_id, :sku, :name, :price, :attributes, :created_at, :updated_at)
+		`
+		_, err := tx.NamedExecContext(ctx, query, variant)
+		return err
+	})
+}
+
+// ListVariants retrieves the variants of a live product in creation order
+func (r *ProductRepository) ListVariants(ctx context.Context, productID uuid.UUID) ([]models.ProductVariant, error) {
+	if _, err := r.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	variants := []models.ProductVariant{}
+	query := `SELECT * FROM product_variants WHERE product_id = $1 ORDER BY created_at ASC, id ASC`
+
+	if err := r.db.SelectContext(ctx, &variants, query, productID); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// GetVariant retrieves a single variant of a live product
+func (r *ProductRepository) GetVariant(ctx context.Context, productID, variantID uuid.UUID) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	query := `
+		SELECT v.* FROM product_variants v
+		JOIN products p ON p.id = v.product_id
+		WHERE v.id = $1 AND v.product_id = $2 AND p.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &variant, query, variantID, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// UpdateVariant replaces the editable fields of a variant
+func (r *ProductRepository) UpdateVariant(ctx context.Context, productID, variantID uuid.UUID, req *models.VariantRequest) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := r.lockProduct(ctx, tx, productID, false); err != nil {
+			return err
+		}
+
+		query := `SELECT * FROM product_variants WHERE id = $1 AND product_id = $2 FOR UPDATE`
+		err := tx.GetContext(ctx, &variant, query, variantID, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVariantNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		req.ApplyTo(&variant)
+		variant.UpdatedAt = time.Now()
+
+		query = `
+			UPDATE product_variants
+			SET sku = :sku,
+				name = :name,
+				price = :price,
+				attributes = :attributes,
+				updated_at = :updated_at
+			WHERE id = :id
+		`
+		_, err = tx.NamedExecContext(ctx, query, variant)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// DeleteVariant permanently removes a variant of a live product together with its stock level
+func (r *ProductRepository) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := r.lockProduct(ctx, tx, productID, false); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM product_variants WHERE id = $1 AND product_id = $2`, variantID, productID)
+		if err != nil {
+			return err
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrVariantNotFound
+		}
+
+		// The ledger keeps the variant's movements; only its current level goes
+		_, err = tx.ExecContext(ctx, `DELETE FROM stock_levels WHERE product_id = $1 AND variant_id = $2`, productID, variantID)
+		return err
+	})
+}
+
+// includeVariants embeds the requested variant data into the products
+func (r *ProductRepository) includeVariants(ctx context.Context, products []models.Product, include models.ProductIncludes) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make(models.UUIDArray, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+
+	var variants []models.ProductVariant
+	query := `SELECT * FROM product_variants WHERE product_id = ANY($1::uuid[]) ORDER BY created_at ASC, id ASC`
+	if err := r.db.SelectContext(ctx, &variants, query, ids); err != nil {
+		return err
+	}
+
+	models.AttachVariants(products, variants, include)
+	return nil
+}
@@ -82,15 +82,200 @@ func InitSchema(db *sqlx.DB) error {
 	schema := `
 	CREATE TABLE IF NOT EXISTS products (
 		id UUID PRIMARY KEY,
+		sku VARCHAR(64) NOT NULL DEFAULT '',
 		name VARCHAR(255) NOT NULL,
 		description TEXT,
 		price DECIMAL(10,2) NOT NULL,
+		category_ids UUID[] NOT NULL DEFAULT '{}',
+		tags TEXT[] NOT NULL DEFAULT '{}',
+		attributes JSONB NOT NULL DEFAULT '{}',
+		status VARCHAR(16) NOT NULL DEFAULT 'active',
 		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		version BIGINT NOT NULL DEFAULT 1,
+		deleted_at TIMESTAMP WITH TIME ZONE
 	);
 
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64) NOT NULL DEFAULT '';
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS category_ids UUID[] NOT NULL DEFAULT '{}';
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+	-- Products that predate the lifecycle were already visible, so they start out active
+	ALTER TABLE products ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'active';
+
 	CREATE INDEX IF NOT EXISTS idx_product_name ON products(name);
 	CREATE INDEX IF NOT EXISTS idx_product_price ON products(price);
+	CREATE INDEX IF NOT EXISTS idx_product_status ON products(status);
+	CREATE INDEX IF NOT EXISTS idx_product_deleted_at ON products(deleted_at) WHERE deleted_at IS NOT NULL;
+
+	-- A SKU identifies one live product; tombstones release it
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_product_sku ON products(sku) WHERE sku <> '' AND deleted_at IS NULL;
+
+	CREATE INDEX IF NOT EXISTS idx_product_category_ids ON products USING GIN (category_ids);
+	CREATE INDEX IF NOT EXISTS idx_product_tags ON products USING GIN (tags);
+	CREATE INDEX IF NOT EXISTS idx_product_attributes ON products USING GIN (attributes jsonb_path_ops);
+
+	-- Autocomplete matches normalized names by prefix and by trigram similarity
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+	CREATE INDEX IF NOT EXISTS idx_product_name_trgm ON products
+		USING GIN ((btrim(regexp_replace(lower(name), '[^[:alnum:]]+', ' ', 'g'))) gin_trgm_ops);
+
+	-- Full-text search; queries must repeat this expression to use the index
+	CREATE INDEX IF NOT EXISTS idx_product_search ON products USING GIN ((
+		setweight(to_tsvector('english', name), 'A') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'B')
+	));
+
+	CREATE TABLE IF NOT EXISTS categories (
+		id UUID PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		parent_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+		attribute_schema JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	ALTER TABLE categories ADD COLUMN IF NOT EXISTS attribute_schema JSONB NOT NULL DEFAULT '{}';
+
+	CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
+
+	CREATE TABLE IF NOT EXISTS product_variants (
+		id UUID PRIMARY KEY,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		sku VARCHAR(64) NOT NULL,
+		name VARCHAR(255) NOT NULL DEFAULT '',
+		price DECIMAL(10,2) NOT NULL,
+		attributes JSONB NOT NULL DEFAULT '{}',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_product_variants_product ON product_variants(product_id, created_at);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variant_sku ON product_variants(sku);
+
+	-- Names and descriptions in other locales than the default one stored on products
+	CREATE TABLE IF NOT EXISTS product_translations (
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		locale VARCHAR(35) NOT NULL,
+		name VARCHAR(255) NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (product_id, locale)
+	);
+
+	-- Image content lives in blob storage; rows only keep the keys
+	CREATE TABLE IF NOT EXISTS product_images (
+		id UUID PRIMARY KEY,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		filename VARCHAR(255) NOT NULL DEFAULT '',
+		content_type VARCHAR(64) NOT NULL,
+		size BIGINT NOT NULL,
+		width INT NOT NULL,
+		height INT NOT NULL,
+		position INT NOT NULL,
+		is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+		blob_key TEXT NOT NULL,
+		thumbnail_key TEXT NOT NULL,
+		thumbnail_content_type VARCHAR(64) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_product_images_product ON product_images(product_id, position);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary ON product_images(product_id) WHERE is_primary;
+
+	-- Product-level stock uses the nil variant ID so the key never holds NULL
+	CREATE TABLE IF NOT EXISTS stock_levels (
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		variant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+		on_hand BIGINT NOT NULL DEFAULT 0,
+		reserved BIGINT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (product_id, variant_id),
+		CHECK (reserved >= 0 AND on_hand >= reserved)
+	);
+
+	CREATE TABLE IF NOT EXISTS stock_movements (
+		id BIGSERIAL PRIMARY KEY,
+		product_id UUID NOT NULL,
+		variant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+		kind VARCHAR(16) NOT NULL,
+		quantity BIGINT NOT NULL,
+		on_hand BIGINT NOT NULL,
+		reserved BIGINT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		actor VARCHAR(255) NOT NULL,
+		request_id VARCHAR(255) NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id, id);
+
+	CREATE TABLE IF NOT EXISTS product_history (
+		id BIGSERIAL PRIMARY KEY,
+		product_id UUID NOT NULL,
+		version BIGINT NOT NULL,
+		action VARCHAR(32) NOT NULL,
+		actor VARCHAR(255) NOT NULL,
+		request_id VARCHAR(255) NOT NULL DEFAULT '',
+		before JSONB,
+		after JSONB,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_product_history_product ON product_history(product_id, version);
+
+	CREATE TABLE IF NOT EXISTS product_prices (
+		id BIGSERIAL PRIMARY KEY,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		price DECIMAL(10,2) NOT NULL,
+		effective_from TIMESTAMP WITH TIME ZONE NOT NULL,
+		effective_to TIMESTAMP WITH TIME ZONE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_product_prices_product ON product_prices(product_id, effective_from);
+
+	CREATE TABLE IF NOT EXISTS product_price_schedules (
+		id UUID PRIMARY KEY,
+		product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+		price DECIMAL(10,2) NOT NULL,
+		starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		ends_at TIMESTAMP WITH TIME ZONE,
+		status VARCHAR(16) NOT NULL,
+		previous_price DECIMAL(10,2),
+		applied_at TIMESTAMP WITH TIME ZONE,
+		reverted_at TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_price_schedules_product ON product_price_schedules(product_id, starts_at);
+	CREATE INDEX IF NOT EXISTS idx_price_schedules_due ON product_price_schedules(status, starts_at) WHERE status IN ('pending', 'active');
+	ALTER TABLE product_price_schedules ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+	ALTER TABLE product_price_schedules ADD COLUMN IF NOT EXISTS last_error TEXT;
+	ALTER TABLE product_price_schedules ADD COLUMN IF NOT EXISTS retry_at TIMESTAMP WITH TIME ZONE;
+
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id UUID PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		prefix VARCHAR(16) NOT NULL,
+		key_hash CHAR(64) NOT NULL UNIQUE,
+		scopes TEXT[] NOT NULL,
+		rate_limit INTEGER NOT NULL,
+		created_by VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP WITH TIME ZONE,
+		revoked_at TIMESTAMP WITH TIME ZONE
+	);
+
+	-- Seed the price series of products created before price history existed
+	INSERT INTO product_prices (product_id, price, effective_from)
+	SELECT p.id, p.price, p.created_at FROM products p
+	WHERE NOT EXISTS (SELECT 1 FROM product_prices pp WHERE pp.product_id = p.id);
 	`
 
 	_, err := db.Exec(schema)
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+// Supported image content types
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+	ContentTypeGIF  = "image/gif"
+)
+
+// MaxPixels bounds the decoded size of an image, so that a small file declaring huge
+// dimensions cannot exhaust memory
+const MaxPixels = 40_000_000
+
+var (
+	// ErrUnsupportedType is returned for content that is not a JPEG, PNG or GIF image
+	ErrUnsupportedType = errors.New("unsupported image type")
+
+	// ErrTooManyPixels is returned for images larger than MaxPixels
+	ErrTooManyPixels = errors.New("image dimensions too large")
+)
+
+// DetectContentType sniffs the content type from the leading bytes of data,
+// ignoring whatever type the client declared
+func DetectContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case ContentTypeJPEG, ContentTypePNG, ContentTypeGIF:
+		return contentType, nil
+	}
+	return contentType, ErrUnsupportedType
+}
+
+// Decode decodes an image of one of the supported content types. The dimensions are
+// checked before any pixels are decoded.
+func Decode(data []byte, contentType string) (image.Image, error) {
+	var decodeConfig func(io.Reader) (image.Config, error)
+	var decode func(io.Reader) (image.Image, error)
+	switch contentType {
+	case ContentTypeJPEG:
+		decodeConfig, decode = jpeg.DecodeConfig, jpeg.Decode
+	case ContentTypePNG:
+		decodeConfig, decode = png.DecodeConfig, png.Decode
+	case ContentTypeGIF:
+		decodeConfig, decode = gif.DecodeConfig, gif.Decode
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	return decode(bytes.NewReader(data))
+}
+
+// Thumbnail scales img down to fit within a size x size box, keeping its aspect ratio.
+// Each thumbnail pixel averages the source pixels it covers. Images that already fit
+// are returned unchanged.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= size && srcH <= size {
+		return img
+	}
+
+	dstW, dstH := size, size
+	if srcW > srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// Encode encodes a thumbnail as JPEG for photographs and as PNG otherwise, so that
+// transparency survives. It returns the encoded bytes and their content type.
+func Encode(img image.Image, sourceType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if sourceType == ContentTypeJPEG {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), ContentTypeJPEG, nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), ContentTypePNG, nil
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"product-service/pkg/logger"
+	"product-service/pkg/requestctx"
+)
+
+const (
+	// APIKeyHeader carries the API key of a service-to-service client
+	APIKeyHeader = "X-API-Key"
+
+	// apiKeyScheme is the Authorization scheme API keys may be sent with instead of APIKeyHeader
+	apiKeyScheme = "ApiKey"
+
+	// apiKeyTouchInterval bounds how often the last use of a key is written to its store
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyIdentity is the client an API key authenticates
+type APIKeyIdentity struct {
+	ID     string
+	Name   string
+	Scopes []string
+
+	// RateLimit is the number of requests per minute the key may make
+	RateLimit int
+}
+
+// HasScope reports whether the key grants scope; the admin scope grants every scope
+func (k *APIKeyIdentity) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyAuthenticator resolves API keys to the clients they identify.
+// Implementations must be safe for concurrent use.
+type APIKeyAuthenticator interface {
+	// AuthenticateAPIKey returns the client of an active key, or nil when the key is unknown or revoked
+	AuthenticateAPIKey(ctx context.Context, key string) (*APIKeyIdentity, error)
+
+	// TouchAPIKey records that the key was used at usedAt
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a copy of ctx carrying the client an API key authenticated
+func WithAPIKey(ctx context.Context, identity *APIKeyIdentity) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, identity)
+}
+
+// APIKeyFromContext returns the client an API key authenticated for the request, if any
+func APIKeyFromContext(ctx context.Context) (*APIKeyIdentity, bool) {
+	identity, ok := ctx.Value(apiKeyContextKey{}).(*APIKeyIdentity)
+	return identity, ok
+}
+
+// apiKeyClient is the per-key state kept between requests
+type apiKeyClient struct {
+	limiter     *rate.Limiter
+	lastTouched time.Time
+}
+
+// APIKeyMiddleware authenticates requests carrying an API key in the X-API-Key header or as
+// "Authorization: ApiKey <key>". The key must grant read for GET, HEAD and OPTIONS requests and
+// write for the others, and may make RateLimit requests per minute in bursts of up to ten
+// seconds' worth; excess requests receive 429 with Retry-After. The key becomes the audit actor
+// and its last use is recorded at most once a minute. Requests without a key are passed on
+// untouched, so that another authentication middleware can handle them.
+func APIKeyMiddleware(authenticator APIKeyAuthenticator) echo.MiddlewareFunc {
+	var (
+		clients = make(map[string]*apiKeyClient)
+		mutex   sync.Mutex
+	)
+
+	// admit applies the rate limit of a key and reports whether its last use should be recorded
+	admit := func(identity *APIKeyIdentity, now time.Time) (allowed, touch bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		client, ok := clients[identity.ID]
+		if !ok {
+			perSecond := rate.Limit(float64(identity.RateLimit) / 60)
+			client = &apiKeyClient{limiter: rate.NewLimiter(perSecond, max(1, identity.RateLimit/6))}
+			clients[identity.ID] = client
+		}
+
+		if !client.limiter.AllowN(now, 1) {
+			return false, false
+		}
+		if now.Sub(client.lastTouched) < apiKeyTouchInterval {
+			return true, false
+		}
+		client.lastTouched = now
+		return true, true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := apiKeyFromRequest(req)
+			if key == "" {
+				return next(c)
+			}
+
+			log := logger.GetLogger()
+			ctx := req.Context()
+			identity, err := authenticator.AuthenticateAPIKey(ctx, key)
+			if err != nil {
+				log.Error("Failed to authenticate API key",
+					zap.Error(err),
+					zap.String("path", req.URL.Path),
+				)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to authenticate API key"})
+			}
+			if identity == nil {
+				log.Warn("Rejected API key",
+					zap.String("path", req.URL.Path),
+					zap.String("remote_ip", c.RealIP()),
+				)
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, apiKeyScheme+` realm="product-service"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+			}
+
+			scope := ScopeWrite
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				scope = ScopeRead
+			}
+			if !identity.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "API key lacks the " + scope + " scope"})
+			}
+
+			now := time.Now()
+			allowed, touch := admit(identity, now)
+			if !allowed {
+				retryAfter := math.Ceil(60 / float64(identity.RateLimit))
+				c.Response().Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "API key rate limit exceeded"})
+			}
+			if touch {
+				if err := authenticator.TouchAPIKey(ctx, identity.ID, now); err != nil {
+					log.Warn("Failed to record API key use",
+						zap.Error(err),
+						zap.String("api_key_id", identity.ID),
+					)
+				}
+			}
+
+			ctx = WithAPIKey(ctx, identity)
+			ctx = requestctx.WithActor(ctx, "api-key:"+identity.ID)
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// apiKeyFromRequest extracts the API key of a request, or "" when it carries none
+func apiKeyFromRequest(req *http.Request) string {
+	if key := strings.TrimSpace(req.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	scheme, key, _ := strings.Cut(req.Header.Get(echo.HeaderAuthorization), " ")
+	if strings.EqualFold(scheme, apiKeyScheme) {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
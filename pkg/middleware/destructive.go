@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/pkg/logger"
+	"product-service/pkg/requestctx"
+)
+
+// ConfirmCountHeader carries the number of rows the caller expects a destructive operation to act on
+const ConfirmCountHeader = "X-Confirm-Count"
+
+// RowCounter reports how many rows a destructive request is about to act on. It may return an
+// *echo.HTTPError to reject the request with a client error.
+type RowCounter func(c echo.Context) (int, error)
+
+// DestructiveConfig configures DestructiveGuard
+type DestructiveConfig struct {
+	// Enabled allows destructive operations at all; production deployments leave it off
+	Enabled bool
+
+	// DryRun marks operations whose handler honours dryRun=true by writing nothing; their dry runs
+	// need no confirmation. Other operations reject the parameter.
+	DryRun bool
+}
+
+// DestructiveGuard protects an operation that removes or overwrites data in bulk. The request
+// must come from an administrator and confirm, through the X-Confirm-Count header, the number of
+// rows count reports; a missing confirmation is answered with 428 and a stale one with 412, both
+// carrying the current count. Where config.DryRun allows it, dry runs (dryRun=true) write
+// nothing and need no confirmation. Every attempt, allowed or not, is written to the audit log.
+func DestructiveGuard(config DestructiveConfig, operation string, count RowCounter) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			log := logger.GetLogger()
+			ctx := c.Request().Context()
+			audit := func(outcome string, fields ...zap.Field) {
+				log.Warn("Destructive operation audit",
+					append([]zap.Field{
+						zap.String("operation", operation),
+						zap.String("outcome", outcome),
+						zap.String("actor", requestctx.Actor(ctx)),
+						zap.String("request_id", requestctx.RequestID(ctx)),
+						zap.String("remote_ip", c.RealIP()),
+						zap.String("uri", c.Request().RequestURI),
+					}, fields...)...,
+				)
+			}
+
+			if !config.Enabled {
+				audit("disabled")
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Destructive operations are disabled in this environment"})
+			}
+			if !IsAdmin(c) {
+				audit("forbidden")
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Destructive operations require administrator access"})
+			}
+
+			if c.QueryParams().Has("dryRun") {
+				if !config.DryRun {
+					audit("rejected", zap.String("reason", "dry run not supported"))
+					return c.JSON(http.StatusBadRequest, map[string]string{"error": "dryRun is not supported for this operation"})
+				}
+				if dryRun, _ := strconv.ParseBool(c.QueryParam("dryRun")); dryRun {
+					err := next(c)
+					audit("dry_run", zap.Int("status", c.Response().Status), zap.Error(err))
+					return err
+				}
+			}
+
+			rows, err := count(c)
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				audit("rejected", zap.Error(err))
+				return c.JSON(httpErr.Code, map[string]interface{}{"error": httpErr.Message})
+			}
+			if err != nil {
+				log.Error("Failed to count rows for destructive operation",
+					zap.Error(err),
+					zap.String("operation", operation),
+				)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to confirm operation"})
+			}
+
+			confirmation := c.Request().Header.Get(ConfirmCountHeader)
+			if confirmation == "" {
+				audit("unconfirmed", zap.Int("row_count", rows))
+				return c.JSON(http.StatusPreconditionRequired, map[string]interface{}{
+					"error":    "Confirm the operation by sending " + ConfirmCountHeader + " with the number of affected products",
+					"rowCount": rows,
+				})
+			}
+			if confirmed, err := strconv.Atoi(confirmation); err != nil || confirmed != rows {
+				audit("stale_confirmation", zap.Int("row_count", rows), zap.String("confirmation", confirmation))
+				return c.JSON(http.StatusPreconditionFailed, map[string]interface{}{
+					"error":    ConfirmCountHeader + " does not match the number of affected products",
+					"rowCount": rows,
+				})
+			}
+
+			err = next(c)
+			audit("executed",
+				zap.Int("row_count", rows),
+				zap.Int("status", c.Response().Status),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/pkg/logger"
+	"product-service/pkg/requestctx"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key that makes a POST safe to retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader marks responses replayed from the idempotency store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength bounds the size of client-supplied keys
+	maxIdempotencyKeyLength = 255
+
+	// inFlightPollInterval is how often a duplicate request checks whether the original finished
+	inFlightPollInterval = 25 * time.Millisecond
+)
+
+// IdempotencyRecord is the state stored for one idempotency key
+type IdempotencyRecord struct {
+	// RequestHash fingerprints the method, path and body of the original request
+	RequestHash string
+
+	// Completed is false while the original request is still being processed
+	Completed bool
+
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyStore persists idempotency records. Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Reserve claims key for a request with the given hash until ttl elapses.
+	// When the key is already claimed it returns the existing record and reserved is false.
+	// A store without room for the reservation returns ErrIdempotencyStoreFull.
+	Reserve(ctx context.Context, key, requestHash string, ttl time.Duration) (existing *IdempotencyRecord, reserved bool, err error)
+
+	// Complete stores the response of a reserved key until ttl elapses
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+
+	// Release forgets a reservation whose response should not be replayed
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures IdempotencyMiddleware
+type IdempotencyConfig struct {
+	// Store holds reservations and stored responses
+	Store IdempotencyStore
+
+	// TTL is how long a stored response is replayed for
+	TTL time.Duration
+
+	// InFlightTimeout bounds how long a duplicate waits for the original request to finish
+	InFlightTimeout time.Duration
+
+	// Lease bounds how long a key is held for a request still in flight, so that a request that
+	// never completes does not block its key for the whole TTL; it should exceed the request timeout
+	Lease time.Duration
+
+	// MaxBodyBytes bounds the request body buffered for the fingerprint; larger requests carrying
+	// an Idempotency-Key are rejected with 413
+	MaxBodyBytes int64
+}
+
+// IdempotencyMiddleware makes POST requests carrying an Idempotency-Key header safe to retry.
+// The first response for a key is stored and replayed for repeats with the same payload;
+// reusing a key with a different payload is rejected with 422. A duplicate arriving while the
+// original is still running waits for it. Server errors and failed preconditions are not stored,
+// so they can be retried.
+// Bodies above MaxBodyBytes are rejected with 413 rather than buffered.
+func IdempotencyMiddleware(config IdempotencyConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(IdempotencyKeyHeader)
+			if req.Method != http.MethodPost || key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Idempotency-Key is too long"})
+			}
+
+			log := logger.GetLogger()
+
+			// Read the body for the fingerprint and hand the handler a fresh copy
+			tooLarge := fmt.Sprintf("Requests with an Idempotency-Key are limited to %d bytes", config.MaxBodyBytes)
+			if req.ContentLength > config.MaxBodyBytes {
+				return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": tooLarge})
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(c.Response(), req.Body, config.MaxBodyBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": tooLarge})
+				}
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Keys are scoped to the verified caller so clients cannot collide with each other
+			ctx := req.Context()
+			storeKey := idempotencyScope(req) + ":" + key
+			requestHash := hashRequest(req, body)
+
+			existing, reserved, err := waitForReservation(ctx, config, storeKey, requestHash)
+			if errors.Is(err, ErrIdempotencyStoreFull) {
+				log.Warn("Idempotency store is full",
+					zap.String("idempotency_key", key),
+				)
+				return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Too many requests with an Idempotency-Key are in flight"})
+			}
+			if err != nil {
+				log.Error("Idempotency store unavailable",
+					zap.Error(err),
+					zap.String("idempotency_key", key),
+				)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to process Idempotency-Key"})
+			}
+
+			if !reserved {
+				if existing.RequestHash != requestHash {
+					log.Warn("Idempotency key reused with a different payload",
+						zap.String("idempotency_key", key),
+						zap.String("path", req.URL.Path),
+					)
+					return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Idempotency-Key was already used with a different request"})
+				}
+				if !existing.Completed {
+					return c.JSON(http.StatusConflict, map[string]string{"error": "A request with this Idempotency-Key is still being processed"})
+				}
+
+				log.Info("Replaying idempotent response",
+					zap.String("idempotency_key", key),
+					zap.Int("status", existing.StatusCode),
+				)
+				return replayResponse(c, existing)
+			}
+
+			// Capture the response while it is written to the client
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// Release the key unless the response is stored, including when the handler panics
+			stored := false
+			defer func() {
+				if stored {
+					return
+				}
+				if err := config.Store.Release(context.Background(), storeKey); err != nil {
+					log.Error("Failed to release idempotency key",
+						zap.Error(err),
+						zap.String("idempotency_key", key),
+					)
+				}
+			}()
+
+			handlerErr := next(c)
+
+			// Errors handled by the error handler, server errors and failed preconditions are left retryable
+			status := c.Response().Status
+			if handlerErr != nil || status >= http.StatusInternalServerError || !c.Response().Committed || isPreconditionFailure(status) {
+				return handlerErr
+			}
+
+			record := IdempotencyRecord{
+				RequestHash: requestHash,
+				Completed:   true,
+				StatusCode:  status,
+				Header:      replayableHeader(c.Response().Header()),
+				Body:        recorder.body.Bytes(),
+			}
+			if err := config.Store.Complete(context.Background(), storeKey, record, config.TTL); err != nil {
+				log.Error("Failed to store idempotent response",
+					zap.Error(err),
+					zap.String("idempotency_key", key),
+				)
+				return nil
+			}
+			stored = true
+			return nil
+		}
+	}
+}
+
+// waitForReservation reserves the key for the lease, waiting while another request holds it in
+// flight. It gives up and returns the in-flight record once InFlightTimeout has elapsed.
+func waitForReservation(ctx context.Context, config IdempotencyConfig, key, requestHash string) (*IdempotencyRecord, bool, error) {
+	deadline := time.Now().Add(config.InFlightTimeout)
+	for {
+		existing, reserved, err := config.Store.Reserve(ctx, key, requestHash, config.Lease)
+		if err != nil || reserved || existing.Completed || existing.RequestHash != requestHash || !time.Now().Before(deadline) {
+			return existing, reserved, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return existing, false, nil
+		case <-time.After(inFlightPollInterval):
+		}
+	}
+}
+
+// isPreconditionFailure reports whether a response rejected a precondition carried in headers the
+// fingerprint does not cover, such as If-Match or X-Confirm-Count. Such responses are not stored,
+// so a retry with the same key and a fresh precondition is evaluated anew.
+func isPreconditionFailure(status int) bool {
+	return status == http.StatusPreconditionFailed || status == http.StatusPreconditionRequired
+}
+
+// idempotencyScope identifies the verified caller owning an Idempotency-Key; the middleware must
+// run after the API key and JWT middlewares. The self-declared X-Actor label is ignored, so
+// unauthenticated callers share one scope.
+func idempotencyScope(req *http.Request) string {
+	ctx := req.Context()
+	if identity, ok := APIKeyFromContext(ctx); ok {
+		return "api-key:" + identity.ID
+	}
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return "jwt:" + claims.Subject
+	}
+	if hasAdminToken(req) {
+		return AdminTokenActor
+	}
+	return requestctx.AnonymousActor
+}
+
+// hashRequest fingerprints the parts of a request that must match for a replay
+func hashRequest(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, req.Method)
+	hash.Write([]byte{0})
+	io.WriteString(hash, req.URL.RequestURI())
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replayableHeader copies the response headers worth replaying, dropping per-request ones
+func replayableHeader(header http.Header) http.Header {
+	replayable := header.Clone()
+	replayable.Del(echo.HeaderXRequestID)
+	return replayable
+}
+
+// replayResponse writes a stored response to the client
+func replayResponse(c echo.Context, record *IdempotencyRecord) error {
+	header := c.Response().Header()
+	for name, values := range record.Header {
+		header[name] = values
+	}
+	header.Set(IdempotentReplayedHeader, "true")
+
+	c.Response().WriteHeader(record.StatusCode)
+	_, err := c.Response().Write(record.Body)
+	return err
+}
+
+// responseRecorder copies the response body while passing it through to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements http.ResponseWriter
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
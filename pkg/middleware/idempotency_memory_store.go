@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyRecordTooLarge is returned when a response does not fit into the store at all
+var ErrIdempotencyRecordTooLarge = errors.New("idempotency record exceeds the store size limit")
+
+// ErrIdempotencyStoreFull is returned when requests in flight hold the whole size limit
+var ErrIdempotencyStoreFull = errors.New("idempotency store is full")
+
+// memoryIdempotencyEntry is a queued record together with its key, expiry and approximate size
+type memoryIdempotencyEntry struct {
+	key       string
+	record    IdempotencyRecord
+	expiresAt time.Time
+	size      int64
+}
+
+// MemoryIdempotencyStore keeps idempotency records in process memory.
+// Records are lost on restart and are not shared between replicas.
+//
+// Entries are queued in the order they were stored. Expired entries at the head of the queue are
+// dropped on every call. Once the records exceed the size limit, the oldest completed or expired
+// ones are evicted early; reservations of requests still in flight are never evicted, so new
+// reservations are refused while they hold the whole limit.
+type MemoryIdempotencyStore struct {
+	entries  map[string]*list.Element
+	queue    *list.List
+	size     int64
+	maxBytes int64
+	mutex    sync.Mutex
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory idempotency store holding up to maxBytes
+// of keys, headers and response bodies
+func NewMemoryIdempotencyStore(maxBytes int64) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries:  make(map[string]*list.Element),
+		queue:    list.New(),
+		maxBytes: maxBytes,
+	}
+}
+
+// Reserve claims key for a request with the given hash until ttl elapses
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key, requestHash string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if element, ok := s.entries[key]; ok {
+		entry := element.Value.(*memoryIdempotencyEntry)
+		if now.Before(entry.expiresAt) {
+			record := entry.record
+			return &record, false, nil
+		}
+		s.remove(key)
+	}
+
+	if err := s.store(key, IdempotencyRecord{RequestHash: requestHash}, now, ttl); err != nil {
+		return nil, false, err
+	}
+	return nil, true, nil
+}
+
+// Complete stores the response of a reserved key until ttl elapses
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.remove(key)
+	return s.store(key, record, time.Now(), ttl)
+}
+
+// Release forgets a reservation whose response should not be replayed
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.remove(key)
+	return nil
+}
+
+// store appends an entry to the queue, first evicting the oldest completed or expired entries
+// to make room for it; callers must hold the lock
+func (s *MemoryIdempotencyStore) store(key string, record IdempotencyRecord, now time.Time, ttl time.Duration) error {
+	size := recordSize(key, record)
+	if size > s.maxBytes {
+		return ErrIdempotencyRecordTooLarge
+	}
+
+	for element := s.queue.Front(); element != nil && s.size+size > s.maxBytes; {
+		entry := element.Value.(*memoryIdempotencyEntry)
+		element = element.Next()
+		if entry.record.Completed || !now.Before(entry.expiresAt) {
+			s.remove(entry.key)
+		}
+	}
+	if s.size+size > s.maxBytes {
+		return ErrIdempotencyStoreFull
+	}
+
+	entry := &memoryIdempotencyEntry{
+		key:       key,
+		record:    record,
+		expiresAt: now.Add(ttl),
+		size:      size,
+	}
+	s.entries[key] = s.queue.PushBack(entry)
+	s.size += entry.size
+	return nil
+}
+
+// remove drops the entry of a key, if any; callers must hold the lock
+func (s *MemoryIdempotencyStore) remove(key string) {
+	element, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	s.size -= element.Value.(*memoryIdempotencyEntry).size
+	s.queue.Remove(element)
+	delete(s.entries, key)
+}
+
+// sweep drops the expired entries at the head of the queue; callers must hold the lock.
+// Expired reservations further back are dropped when they are looked up or evicted.
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	for element := s.queue.Front(); element != nil; element = s.queue.Front() {
+		entry := element.Value.(*memoryIdempotencyEntry)
+		if now.Before(entry.expiresAt) {
+			return
+		}
+		s.remove(entry.key)
+	}
+}
+
+// recordSize approximates the memory held by a record
+func recordSize(key string, record IdempotencyRecord) int64 {
+	size := len(key) + len(record.RequestHash) + len(record.Body)
+	for name, values := range record.Header {
+		size += len(name)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+	return int64(size)
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newIdempotencyTestConfig returns a config with a fresh in-memory store
+func newIdempotencyTestConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		Store:           NewMemoryIdempotencyStore(1 << 20),
+		TTL:             time.Hour,
+		InFlightTimeout: 100 * time.Millisecond,
+		Lease:           time.Minute,
+		MaxBodyBytes:    1 << 10,
+	}
+}
+
+// postWithKey sends a POST carrying an Idempotency-Key and the given headers as name/value pairs
+func postWithKey(e *echo.Echo, path, key, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysCompletedResponses(t *testing.T) {
+	e := echo.New()
+	calls := 0
+	e.POST("/products", func(c echo.Context) error {
+		calls++
+		return c.JSON(http.StatusCreated, map[string]int{"call": calls})
+	}, IdempotencyMiddleware(newIdempotencyTestConfig()))
+
+	first := postWithKey(e, "/products", "key-1", `{"name":"A"}`)
+	second := postWithKey(e, "/products", "key-1", `{"name":"A"}`)
+	if calls != 1 || second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Fatalf("got %d calls and replay %d %s", calls, second.Code, second.Body)
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("replay is not marked")
+	}
+
+	if rec := postWithKey(e, "/products", "key-1", `{"name":"B"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got %d for a reused key with another body", rec.Code)
+	}
+}
+
+func TestIdempotencyLetsDestructiveOperationsBeConfirmed(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "admin-secret")
+	e := echo.New()
+	executed := 0
+	e.POST("/products/bulk/delete", func(c echo.Context) error {
+		executed++
+		return c.JSON(http.StatusOK, map[string]int{"affected": 3})
+	},
+		IdempotencyMiddleware(newIdempotencyTestConfig()),
+		DestructiveGuard(DestructiveConfig{Enabled: true}, "bulk_delete", func(c echo.Context) (int, error) { return 3, nil }),
+	)
+
+	body := `{"filter":{"category":"x"}}`
+	admin := []string{AdminTokenHeader, "admin-secret"}
+
+	if rec := postWithKey(e, "/products/bulk/delete", "delete-1", body, admin...); rec.Code != http.StatusPreconditionRequired {
+		t.Fatalf("got %d without confirmation, want 428", rec.Code)
+	}
+	stale := append([]string{ConfirmCountHeader, "2"}, admin...)
+	if rec := postWithKey(e, "/products/bulk/delete", "delete-1", body, stale...); rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("got %d with a stale confirmation, want 412", rec.Code)
+	}
+
+	confirmed := append([]string{ConfirmCountHeader, "3"}, admin...)
+	rec := postWithKey(e, "/products/bulk/delete", "delete-1", body, confirmed...)
+	if rec.Code != http.StatusOK || executed != 1 {
+		t.Fatalf("got %d and %d executions after confirming, want 200 and 1", rec.Code, executed)
+	}
+	if rec.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatal("confirmed request was answered from the store")
+	}
+
+	// The confirmed response is what retries replay
+	rec = postWithKey(e, "/products/bulk/delete", "delete-1", body, confirmed...)
+	if rec.Code != http.StatusOK || executed != 1 || rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("got %d and %d executions on retry, want a replayed 200", rec.Code, executed)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	e := echo.New()
+	calls := 0
+	e.POST("/products", func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return c.JSON(http.StatusCreated, map[string]int{"call": calls})
+	}, RecoverMiddleware(), IdempotencyMiddleware(newIdempotencyTestConfig()))
+
+	if rec := postWithKey(e, "/products", "key-1", `{}`); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d for the panicking request, want 500", rec.Code)
+	}
+	if rec := postWithKey(e, "/products", "key-1", `{}`); rec.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("got %d after %d calls, want the retry to run", rec.Code, calls)
+	}
+}
+
+func TestIdempotencyDoesNotReplayFailedPreconditions(t *testing.T) {
+	e := echo.New()
+	e.POST("/products/:id/publish", func(c echo.Context) error {
+		if c.Request().Header.Get("If-Match") != `"2"` {
+			return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": "stale"})
+		}
+		return c.JSON(http.StatusOK, map[string]string{"status": "active"})
+	}, IdempotencyMiddleware(newIdempotencyTestConfig()))
+
+	if rec := postWithKey(e, "/products/1/publish", "key-1", ``, "If-Match", `"1"`); rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("got %d with a stale tag, want 412", rec.Code)
+	}
+	if rec := postWithKey(e, "/products/1/publish", "key-1", ``, "If-Match", `"2"`); rec.Code != http.StatusOK {
+		t.Fatalf("got %d after refreshing the tag, want 200", rec.Code)
+	}
+}
+
+func TestMemoryIdempotencyStoreLeasesExpire(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore(1 << 10)
+
+	if _, reserved, err := store.Reserve(ctx, "key", "hash", time.Millisecond); !reserved || err != nil {
+		t.Fatalf("got reserved=%v, err=%v", reserved, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, reserved, err := store.Reserve(ctx, "key", "hash", time.Hour); !reserved || err != nil {
+		t.Fatalf("expired lease still holds the key: reserved=%v, err=%v", reserved, err)
+	}
+}
+
+func TestMemoryIdempotencyStoreNeverEvictsInFlightReservations(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore(40)
+
+	// Each reservation takes 9 bytes, so four fill the store
+	for _, key := range []string{"key-1", "key-2", "key-3", "key-4"} {
+		if _, reserved, err := store.Reserve(ctx, key, "hash", time.Hour); !reserved || err != nil {
+			t.Fatalf("%s: got reserved=%v, err=%v", key, reserved, err)
+		}
+	}
+	if _, _, err := store.Reserve(ctx, "key-5", "hash", time.Hour); !errors.Is(err, ErrIdempotencyStoreFull) {
+		t.Fatalf("got %v, want ErrIdempotencyStoreFull", err)
+	}
+	for _, key := range []string{"key-1", "key-2", "key-3", "key-4"} {
+		existing, reserved, err := store.Reserve(ctx, key, "hash", time.Hour)
+		if reserved || err != nil || existing.Completed {
+			t.Fatalf("%s: in-flight reservation was lost", key)
+		}
+	}
+
+	// Completed records make room for new reservations, oldest first
+	if err := store.Complete(ctx, "key-2", IdempotencyRecord{RequestHash: "hash", Completed: true}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, reserved, err := store.Reserve(ctx, "key-5", "hash", time.Hour); !reserved || err != nil {
+		t.Fatalf("got reserved=%v, err=%v", reserved, err)
+	}
+	if existing, _, err := store.Reserve(ctx, "key-2", "hash", time.Hour); existing != nil || !errors.Is(err, ErrIdempotencyStoreFull) {
+		t.Fatalf("completed record was kept instead of evicted: %+v, %v", existing, err)
+	}
+}
+
+func TestIdempotencyAnswersUnavailableWhenStoreIsFull(t *testing.T) {
+	config := newIdempotencyTestConfig()
+	config.Store = NewMemoryIdempotencyStore(100)
+
+	// Another request in flight holds most of the store
+	if _, reserved, err := config.Store.Reserve(context.Background(), "anonymous:key-0", strings.Repeat("0", 64), time.Hour); !reserved || err != nil {
+		t.Fatalf("got reserved=%v, err=%v", reserved, err)
+	}
+	e := echo.New()
+	e.POST("/products", func(c echo.Context) error {
+		return c.NoContent(http.StatusCreated)
+	}, IdempotencyMiddleware(config))
+
+	if rec := postWithKey(e, "/products", "key-1", `{}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got %d, want 503", rec.Code)
+	}
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// JWT signing algorithms accepted by JWTMiddleware
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// verificationKey is a public key of a key set and the algorithm it verifies
+type verificationKey struct {
+	KeyID     string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+// KeySet holds the public keys asymmetric tokens are verified with
+type KeySet struct {
+	keys []verificationKey
+}
+
+// jwk is the JSON representation of an RSA or EC public key (RFC 7517)
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+// LoadKeySet reads a JWKS document from a local file. RSA keys verify RS256 tokens and P-256
+// EC keys verify ES256 tokens; keys meant for encryption or of other types are skipped.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JWKS document
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var (
+			parsed    verificationKey
+			err       error
+			algorithm string
+		)
+		switch key.KeyType {
+		case "RSA":
+			parsed.Key, err = parseRSAKey(key)
+			algorithm = AlgRS256
+		case "EC":
+			parsed.Key, err = parseECKey(key)
+			algorithm = AlgES256
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("JWKS key %d: %w", i, err)
+		}
+		if key.Algorithm != "" && key.Algorithm != algorithm {
+			continue
+		}
+
+		parsed.KeyID = key.KeyID
+		parsed.Algorithm = algorithm
+		set.keys = append(set.keys, parsed)
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("JWKS document holds no RS256 or ES256 signing key")
+	}
+	return set, nil
+}
+
+// Find returns the key a token signed with the algorithm and key ID is verified with. A token
+// without a key ID is accepted when exactly one key serves its algorithm.
+func (s *KeySet) Find(algorithm, keyID string) (crypto.PublicKey, bool) {
+	var found crypto.PublicKey
+	matches := 0
+	for _, key := range s.keys {
+		if key.Algorithm != algorithm {
+			continue
+		}
+		if keyID != "" {
+			if key.KeyID == keyID {
+				return key.Key, true
+			}
+			continue
+		}
+		found = key.Key
+		matches++
+	}
+	return found, matches == 1
+}
+
+// parseRSAKey decodes the modulus and exponent of an RSA key
+func parseRSAKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(key.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := decodeBigInt(key.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	if n.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must have at least 2048 bits")
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, errors.New("RSA exponent is out of range")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// parseECKey decodes the coordinates of a P-256 key and checks that the point lies on the curve
+func parseECKey(key jwk) (*ecdsa.PublicKey, error) {
+	if key.Curve != "P-256" {
+		return nil, fmt.Errorf("unsupported curve %q; use P-256", key.Curve)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil || len(x) != 32 {
+		return nil, errors.New("invalid x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(key.Y)
+	if err != nil || len(y) != 32 {
+		return nil, errors.New("invalid y coordinate")
+	}
+
+	// Parsing the uncompressed point rejects points off the curve
+	point := append(append([]byte{4}, x...), y...)
+	if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+		return nil, errors.New("point is not on the P-256 curve")
+	}
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+// decodeBigInt decodes an unpadded base64url big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing value")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
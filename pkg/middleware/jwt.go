@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+
+	"product-service/pkg/logger"
+	"product-service/pkg/requestctx"
+)
+
+// anonymousTokenActor is recorded as the actor of requests whose bearer token has no sub claim
+const anonymousTokenActor = "bearer-token"
+
+// JWTConfig configures JWTMiddleware
+type JWTConfig struct {
+	// Secret verifies HS256 tokens; HS256 is refused when it is empty
+	Secret []byte
+
+	// Keys verifies RS256 and ES256 tokens; they are refused when it is nil
+	Keys *KeySet
+
+	// Issuer, when set, must equal the token's iss claim
+	Issuer string
+
+	// Audience, when set, must be one of the token's aud values
+	Audience string
+
+	// Leeway tolerates clock skew when checking exp, nbf and iat
+	Leeway time.Duration
+}
+
+// Enabled reports whether any verification key is configured
+func (config JWTConfig) Enabled() bool {
+	return len(config.Secret) > 0 || config.Keys != nil
+}
+
+// Claims are the verified claims of a bearer token
+type Claims struct {
+	Subject   string       `json:"sub"`
+	Issuer    string       `json:"iss"`
+	Audience  audience     `json:"aud"`
+	ExpiresAt *NumericDate `json:"exp"`
+	NotBefore *NumericDate `json:"nbf"`
+	IssuedAt  *NumericDate `json:"iat"`
+	Scope     string       `json:"scope"`
+
+	// Raw holds every claim of the token, including those not mapped above
+	Raw map[string]interface{} `json:"-"`
+}
+
+// HasScope reports whether the space-separated scope claim grants scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// NumericDate is a time claim in seconds since the epoch; fractions of a second are dropped
+type NumericDate int64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return errors.New("time claims must be numbers")
+	}
+	*d = NumericDate(seconds)
+	return nil
+}
+
+// audience accepts the aud claim as a single string or a list of strings
+type audience []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("aud must be a string or a list of strings")
+	}
+	*a = list
+	return nil
+}
+
+type claimsContextKey struct{}
+
+// WithClaims returns a copy of ctx carrying verified token claims
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the verified token claims stored in ctx, if any
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// JWTMiddleware requires a valid bearer token on every request of the routes it is applied to,
+// and stores its claims in the request context; the token subject becomes the audit actor.
+// Requests already authenticated by an API key are let through without a token.
+// Rejected requests receive 401 with a WWW-Authenticate challenge.
+func JWTMiddleware(config JWTConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			authorization := req.Header.Get(echo.HeaderAuthorization)
+			if _, ok := APIKeyFromContext(req.Context()); ok {
+				return next(c)
+			}
+
+			scheme, token, _ := strings.Cut(authorization, " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="product-service"`)
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "A bearer token is required"})
+			}
+
+			claims, err := config.Verify(strings.TrimSpace(token), time.Now())
+			if err != nil {
+				logger.GetLogger().Warn("Rejected bearer token",
+					zap.Error(err),
+					zap.String("path", req.URL.Path),
+					zap.String("remote_ip", c.RealIP()),
+				)
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate,
+					fmt.Sprintf(`Bearer realm="product-service", error="invalid_token", error_description=%q`, err.Error()))
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid bearer token: " + err.Error()})
+			}
+
+			// The verified subject always replaces the self-declared X-Actor label
+			actor := claims.Subject
+			if actor == "" {
+				actor = anonymousTokenActor
+			}
+			ctx := WithClaims(req.Context(), claims)
+			ctx = requestctx.WithActor(ctx, actor)
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// Verify checks the signature and the time, issuer and audience claims of a compact JWS token
+func (config JWTConfig) Verify(token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token is malformed")
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errors.New("token header is malformed")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("token signature is malformed")
+	}
+	if err := config.verifySignature(header.Algorithm, header.KeyID, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errors.New("token claims are malformed")
+	}
+	if err := decodeSegment(parts[1], &claims.Raw); err != nil {
+		return nil, errors.New("token claims are malformed")
+	}
+	if err := config.checkClaims(&claims, now); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// verifySignature checks the signature with the key the algorithm calls for. The algorithm
+// decides the kind of key, so a public key is never used as an HMAC secret.
+func (config JWTConfig) verifySignature(algorithm, keyID, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch algorithm {
+	case AlgHS256:
+		if len(config.Secret) == 0 {
+			return errors.New("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, config.Secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("signature is invalid")
+		}
+		return nil
+
+	case AlgRS256, AlgES256:
+		if config.Keys == nil {
+			return fmt.Errorf("%s tokens are not accepted", algorithm)
+		}
+		key, ok := config.Keys.Find(algorithm, keyID)
+		if !ok {
+			return errors.New("no key matches the token")
+		}
+
+		if algorithm == AlgRS256 {
+			if rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) != nil {
+				return errors.New("signature is invalid")
+			}
+			return nil
+		}
+
+		// ES256 signatures are the fixed-size concatenation of r and s
+		if len(signature) != 64 {
+			return errors.New("signature is invalid")
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key.(*ecdsa.PublicKey), digest[:], r, s) {
+			return errors.New("signature is invalid")
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q is not accepted", algorithm)
+}
+
+// checkClaims enforces expiry, not-before, issued-at, issuer and audience
+func (config JWTConfig) checkClaims(claims *Claims, now time.Time) error {
+	leeway := int64(config.Leeway / time.Second)
+	unix := now.Unix()
+
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+	if unix > int64(*claims.ExpiresAt)+leeway {
+		return errors.New("token has expired")
+	}
+	if claims.NotBefore != nil && unix < int64(*claims.NotBefore)-leeway {
+		return errors.New("token is not valid yet")
+	}
+	if claims.IssuedAt != nil && unix < int64(*claims.IssuedAt)-leeway {
+		return errors.New("token was issued in the future")
+	}
+
+	if config.Issuer != "" && claims.Issuer != config.Issuer {
+		return errors.New("token issuer is not accepted")
+	}
+	if config.Audience != "" {
+		for _, value := range claims.Audience {
+			if value == config.Audience {
+				return nil
+			}
+		}
+		return errors.New("token audience is not accepted")
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,367 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+// jwtTestKeys are the signing keys shared by the tests; generating RSA keys is slow
+var jwtTestKeys = struct {
+	rsa   *rsa.PrivateKey
+	ec    *ecdsa.PrivateKey
+	other *ecdsa.PrivateKey
+}{
+	rsa:   mustGenerateRSAKey(),
+	ec:    mustGenerateECKey(),
+	other: mustGenerateECKey(),
+}
+
+func mustGenerateRSAKey() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func mustGenerateECKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signToken builds a compact token; key is a []byte secret, an RSA or EC private key, or nil
+// for an unsigned token
+func signToken(t *testing.T, header map[string]interface{}, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	signingInput := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// validClaims are claims that pass every check at testNow
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": "user-1",
+		"iss": "https://issuer.example",
+		"aud": []string{"other", "product-service"},
+		"exp": testNow.Add(time.Hour).Unix(),
+		"nbf": testNow.Add(-time.Minute).Unix(),
+		"iat": testNow.Add(-time.Minute).Unix(),
+	}
+}
+
+func withClaim(name string, value interface{}) map[string]interface{} {
+	claims := validClaims()
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+	return claims
+}
+
+func encodeCoordinate(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, 32)))
+}
+
+// testKeySet builds a JWKS with the RSA key as "rsa-1" and the EC keys as "ec-1" and "ec-2"
+func testKeySet(t *testing.T) *KeySet {
+	t.Helper()
+	rsaKey := jwtTestKeys.rsa.PublicKey
+	document := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-1",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-1",
+				"crv": "P-256",
+				"x":   encodeCoordinate(jwtTestKeys.ec.X),
+				"y":   encodeCoordinate(jwtTestKeys.ec.Y),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-2",
+				"crv": "P-256",
+				"x":   encodeCoordinate(jwtTestKeys.other.X),
+				"y":   encodeCoordinate(jwtTestKeys.other.Y),
+			},
+		},
+	}
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := ParseKeySet(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func testJWTConfig(t *testing.T) JWTConfig {
+	t.Helper()
+	return JWTConfig{
+		Secret:   []byte("test-secret"),
+		Keys:     testKeySet(t),
+		Issuer:   "https://issuer.example",
+		Audience: "product-service",
+		Leeway:   30 * time.Second,
+	}
+}
+
+func TestVerifyAcceptsValidTokens(t *testing.T) {
+	config := testJWTConfig(t)
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+		key    interface{}
+	}{
+		{"HS256", map[string]interface{}{"alg": "HS256"}, []byte("test-secret")},
+		{"RS256 with kid", map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, jwtTestKeys.rsa},
+		{"RS256 without kid and a single key", map[string]interface{}{"alg": "RS256"}, jwtTestKeys.rsa},
+		{"ES256 with kid", map[string]interface{}{"alg": "ES256", "kid": "ec-1"}, jwtTestKeys.ec},
+		{"ES256 selects the second key by kid", map[string]interface{}{"alg": "ES256", "kid": "ec-2"}, jwtTestKeys.other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := config.Verify(signToken(t, tt.header, validClaims(), tt.key), testNow)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Subject != "user-1" {
+				t.Fatalf("got subject %q", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsBadSignatures(t *testing.T) {
+	config := testJWTConfig(t)
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&jwtTestKeys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keysOnly := config
+	keysOnly.Secret = nil
+
+	validES256 := signToken(t, map[string]interface{}{"alg": "ES256", "kid": "ec-1"}, validClaims(), jwtTestKeys.ec)
+	parts := strings.Split(validES256, ".")
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	withSignature := func(signature []byte) string {
+		return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+	}
+
+	tests := []struct {
+		name   string
+		config JWTConfig
+		token  string
+	}{
+		{
+			name:   "HS256 signed with the RSA public key",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "HS256", "kid": "rsa-1"}, validClaims(), publicKeyDER),
+		},
+		{
+			name:   "HS256 when only public keys are configured",
+			config: keysOnly,
+			token:  signToken(t, map[string]interface{}{"alg": "HS256", "kid": "rsa-1"}, validClaims(), publicKeyDER),
+		},
+		{
+			name:   "HS256 with the wrong secret",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "HS256"}, validClaims(), []byte("other-secret")),
+		},
+		{
+			name:   "alg none",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "none"}, validClaims(), nil),
+		},
+		{
+			name:   "alg None",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "None"}, validClaims(), nil),
+		},
+		{
+			name:   "unknown kid",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "ES256", "kid": "ec-3"}, validClaims(), jwtTestKeys.ec),
+		},
+		{
+			name:   "no kid with two candidate keys",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "ES256"}, validClaims(), jwtTestKeys.ec),
+		},
+		{
+			name:   "kid of another key",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "ES256", "kid": "ec-2"}, validClaims(), jwtTestKeys.ec),
+		},
+		{
+			name:   "kid of a key for another algorithm",
+			config: config,
+			token:  signToken(t, map[string]interface{}{"alg": "RS256", "kid": "ec-1"}, validClaims(), jwtTestKeys.rsa),
+		},
+		{
+			name:   "RS256 without a key set",
+			config: JWTConfig{Secret: []byte("test-secret")},
+			token:  signToken(t, map[string]interface{}{"alg": "RS256", "kid": "rsa-1"}, validClaims(), jwtTestKeys.rsa),
+		},
+		{
+			name:   "ES256 signature one byte short",
+			config: config,
+			token:  withSignature(signature[:63]),
+		},
+		{
+			name:   "ES256 signature one byte long",
+			config: config,
+			token:  withSignature(append(append([]byte{}, signature...), 0)),
+		},
+		{
+			name:   "ES256 signature in ASN.1 form",
+			config: config,
+			token: withSignature(func() []byte {
+				digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+				der, err := ecdsa.SignASN1(rand.Reader, jwtTestKeys.ec, digest[:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				return der
+			}()),
+		},
+		{
+			name:   "tampered claims",
+			config: config,
+			token:  encodeSegment(t, map[string]interface{}{"alg": "ES256", "kid": "ec-1"}) + "." + encodeSegment(t, withClaim("sub", "admin")) + "." + parts[2],
+		},
+		{
+			name:   "malformed token",
+			config: config,
+			token:  parts[0] + "." + parts[1],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := tt.config.Verify(tt.token, testNow); err == nil {
+				t.Fatalf("expected an error, got claims for %q", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksClaims(t *testing.T) {
+	config := testJWTConfig(t)
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		err    string
+	}{
+		{"expired", withClaim("exp", testNow.Add(-time.Minute).Unix()), "token has expired"},
+		{"no expiry", withClaim("exp", nil), "token has no expiry"},
+		{"expired within leeway", withClaim("exp", testNow.Add(-10*time.Second).Unix()), ""},
+		{"not valid yet", withClaim("nbf", testNow.Add(time.Minute).Unix()), "token is not valid yet"},
+		{"not valid yet within leeway", withClaim("nbf", testNow.Add(10*time.Second).Unix()), ""},
+		{"issued in the future", withClaim("iat", testNow.Add(time.Minute).Unix()), "token was issued in the future"},
+		{"wrong issuer", withClaim("iss", "https://other.example"), "token issuer is not accepted"},
+		{"no issuer", withClaim("iss", nil), "token issuer is not accepted"},
+		{"wrong audience", withClaim("aud", "other"), "token audience is not accepted"},
+		{"no audience", withClaim("aud", nil), "token audience is not accepted"},
+		{"audience as a string", withClaim("aud", "product-service"), ""},
+		{"time claim as a string", withClaim("exp", fmt.Sprint(testNow.Add(time.Hour).Unix())), "token claims are malformed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signToken(t, map[string]interface{}{"alg": "HS256"}, tt.claims, []byte("test-secret"))
+			_, err := config.Verify(token, testNow)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("got error %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestJWTMiddlewareRequiresBearerTokenFromAdministrators(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "admin-secret")
+	config := JWTConfig{Secret: []byte("test-secret")}
+	handler := JWTMiddleware(config)(func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+	req.Header.Set(AdminTokenHeader, "admin-secret")
+	rec := httptest.NewRecorder()
+	if err := handler(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec.Header().Get(echo.HeaderWWWAuthenticate) == "" {
+		t.Fatal("missing WWW-Authenticate challenge")
+	}
+}
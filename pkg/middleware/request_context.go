@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"product-service/pkg/requestctx"
+)
+
+// ActorHeader names the caller of an unauthenticated request for audit purposes. It is a
+// self-declared label: requests presenting the administrator token are recorded as
+// AdminTokenActor instead, and the API key and JWT middlewares replace it with the identity they
+// verify.
+const ActorHeader = "X-Actor"
+
+// RequestContextMiddleware copies the request ID and caller identity into the request context
+// so that repositories can attribute the changes they record
+func RequestContextMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			// Prefer the ID generated by the RequestID middleware, falling back to the client's
+			requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+			if requestID == "" {
+				requestID = req.Header.Get(echo.HeaderXRequestID)
+			}
+
+			ctx := requestctx.WithRequestID(req.Context(), requestID)
+			actor := req.Header.Get(ActorHeader)
+			if hasAdminToken(req) {
+				actor = AdminTokenActor
+			}
+			ctx = requestctx.WithActor(ctx, actor)
+			c.SetRequest(req.WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Markers wrapped around matched words in highlighted text
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// Highlight wraps the words of text that match a query term in highlight markers. With a
+// positive maxWords, text longer than that is cut to a fragment of maxWords words that
+// starts shortly before the first match. The text is HTML-escaped, so the result is safe to
+// embed as HTML and only the markers are markup.
+func Highlight(text string, query Query, maxWords int) string {
+	terms := make(map[string]bool, len(query.Terms))
+	for _, term := range query.Terms {
+		terms[term] = true
+	}
+
+	words := wordSpans(text)
+	from, to := 0, len(text)
+	if maxWords > 0 && len(words) > maxWords {
+		first := 0
+		for _, token := range Tokenize(text) {
+			if terms[token.Term] {
+				for first < len(words)-1 && words[first][1] <= token.Start {
+					first++
+				}
+				break
+			}
+		}
+
+		// Keep a little context before the first match
+		start := max(first-3, 0)
+		if start+maxWords > len(words) {
+			start = len(words) - maxWords
+		}
+		from, to = words[start][0], words[start+maxWords-1][1]
+	}
+
+	var b strings.Builder
+	last := from
+	for _, token := range Tokenize(text[from:to]) {
+		if !terms[token.Term] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[last : from+token.Start]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[from+token.Start : from+token.End]))
+		b.WriteString(HighlightStop)
+		last = from + token.End
+	}
+	b.WriteString(html.EscapeString(text[last:to]))
+	return b.String()
+}
+
+// wordSpans returns the byte offsets of the whitespace-separated words of text
+func wordSpans(text string) [][2]int {
+	var spans [][2]int
+	start := -1
+	for i, r := range text + " " {
+		space := r == ' ' || r == '\t' || r == '\n' || r == '\r'
+		if !space && start < 0 {
+			start = i
+		}
+		if space && start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	return spans
+}
@@ -0,0 +1,123 @@
+package search
+
+import "sort"
+
+// Field weights, matching the default weights Postgres ts_rank gives to the A and B labels
+const (
+	WeightTitle = 1.0
+	WeightBody  = 0.4
+)
+
+// Field is a piece of a document's text with the weight its matches count for
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Match is a document matching a query with its relevance score
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// Index is an inverted index from terms to the documents containing them. It is not safe
+// for concurrent use; callers guard it with their own lock.
+type Index struct {
+	// postings maps each term to the weighted frequency of the term in each document
+	postings map[string]map[string]float64
+	// terms lists the distinct terms of each document, for removal
+	terms map[string][]string
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]float64),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Add indexes a document, replacing any earlier version of it
+func (ix *Index) Add(id string, fields ...Field) {
+	ix.Remove(id)
+
+	frequencies := make(map[string]float64)
+	for _, field := range fields {
+		for _, token := range Tokenize(field.Text) {
+			frequencies[token.Term] += field.Weight
+		}
+	}
+
+	terms := make([]string, 0, len(frequencies))
+	for term, frequency := range frequencies {
+		if ix.postings[term] == nil {
+			ix.postings[term] = make(map[string]float64)
+		}
+		ix.postings[term][id] = frequency
+		terms = append(terms, term)
+	}
+	ix.terms[id] = terms
+}
+
+// Remove drops a document from the index
+func (ix *Index) Remove(id string) {
+	for _, term := range ix.terms[id] {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.terms, id)
+}
+
+// Search returns the documents matching the query, most relevant first. Each term adds
+// f/(f+1) to the score for its weighted frequency f, so repeats raise the score with
+// diminishing returns and a title match outweighs a body match. Documents with equal
+// scores are ordered by ID.
+func (ix *Index) Search(query Query) []Match {
+	if query.Empty() {
+		return nil
+	}
+
+	// Start from the rarest term to keep the candidate set small
+	terms := append([]string(nil), query.Terms...)
+	sort.Slice(terms, func(i, j int) bool {
+		return len(ix.postings[terms[i]]) < len(ix.postings[terms[j]])
+	})
+
+	var matches []Match
+	for id := range ix.postings[terms[0]] {
+		score, ok := ix.score(id, terms, query.Excluded)
+		if ok {
+			matches = append(matches, Match{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	return matches
+}
+
+// score rates a document against the terms, reporting false when it lacks one of them or
+// contains an excluded term
+func (ix *Index) score(id string, terms, excluded []string) (float64, bool) {
+	for _, term := range excluded {
+		if _, ok := ix.postings[term][id]; ok {
+			return 0, false
+		}
+	}
+
+	var score float64
+	for _, term := range terms {
+		frequency, ok := ix.postings[term][id]
+		if !ok {
+			return 0, false
+		}
+		score += frequency / (frequency + 1)
+	}
+	return score, true
+}
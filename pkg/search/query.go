@@ -0,0 +1,38 @@
+package search
+
+import "strings"
+
+// Query is a parsed search query. A document matches when it contains every term and none
+// of the excluded terms.
+type Query struct {
+	Terms    []string
+	Excluded []string
+}
+
+// ParseQuery reads a query in the style of web search engines: words are all required and
+// a word prefixed with "-" excludes documents containing it. Quotes are ignored, so a
+// quoted phrase requires its words in any order.
+func ParseQuery(text string) Query {
+	var query Query
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(text) {
+		excluded := strings.HasPrefix(word, "-")
+		for _, token := range Tokenize(word) {
+			if seen[token.Term] {
+				continue
+			}
+			seen[token.Term] = true
+			if excluded {
+				query.Excluded = append(query.Excluded, token.Term)
+			} else {
+				query.Terms = append(query.Terms, token.Term)
+			}
+		}
+	}
+	return query
+}
+
+// Empty reports whether the query has no terms to match, such as one made of stop words only
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0
+}
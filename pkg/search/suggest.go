@@ -0,0 +1,179 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// FuzzyThreshold is the least trigram similarity of a fuzzy suggestion, the default
+// word_similarity_threshold of Postgres pg_trgm
+const FuzzyThreshold = 0.6
+
+// Suggestion is a document suggested for a typed prefix
+type Suggestion struct {
+	ID    string
+	Score float64
+}
+
+// SuggestIndex suggests documents by their name as it is being typed. Names are matched
+// by prefix, then by the prefix of any of their words, then by trigram similarity, which
+// tolerates typos. It is not safe for concurrent use; callers guard it with their own lock.
+type SuggestIndex struct {
+	names    map[string]string
+	words    *trie
+	trigrams map[string]map[string]bool
+}
+
+// NewSuggestIndex creates an empty suggestion index
+func NewSuggestIndex() *SuggestIndex {
+	return &SuggestIndex{
+		names:    make(map[string]string),
+		words:    newTrie(),
+		trigrams: make(map[string]map[string]bool),
+	}
+}
+
+// NormalizeName lowercases text and reduces it to its words of letters and digits separated
+// by single spaces, the form names and prefixes are compared in
+func NormalizeName(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// Add indexes the name of a document, replacing any earlier name
+func (ix *SuggestIndex) Add(id, name string) {
+	ix.Remove(id)
+
+	name = NormalizeName(name)
+	ix.names[id] = name
+	for _, word := range strings.Fields(name) {
+		ix.words.insert(word, id)
+	}
+	for trigram := range trigrams(name) {
+		if ix.trigrams[trigram] == nil {
+			ix.trigrams[trigram] = make(map[string]bool)
+		}
+		ix.trigrams[trigram][id] = true
+	}
+}
+
+// Remove drops a document from the index
+func (ix *SuggestIndex) Remove(id string) {
+	name, ok := ix.names[id]
+	if !ok {
+		return
+	}
+	for _, word := range strings.Fields(name) {
+		ix.words.remove([]rune(word), id)
+	}
+	for trigram := range trigrams(name) {
+		delete(ix.trigrams[trigram], id)
+		if len(ix.trigrams[trigram]) == 0 {
+			delete(ix.trigrams, trigram)
+		}
+	}
+	delete(ix.names, id)
+}
+
+// Suggest returns every document matching the prefix, best first: names starting with the
+// prefix, then names with a word starting with it, then names similar to it. Within each
+// group documents are ordered by similarity, then by name.
+func (ix *SuggestIndex) Suggest(prefix string) []Suggestion {
+	prefix = NormalizeName(prefix)
+	if prefix == "" {
+		return nil
+	}
+	words := strings.Fields(prefix)
+
+	// Names whose words continue the typed ones: all but the last typed word are complete
+	candidates := make(map[string]bool)
+	ix.words.withPrefix(words[len(words)-1], candidates)
+	for _, word := range words[:len(words)-1] {
+		complete := ix.words.exact(word)
+		for id := range candidates {
+			if !complete[id] {
+				delete(candidates, id)
+			}
+		}
+	}
+
+	// Names sharing a trigram with the prefix may be close enough despite a typo
+	query := trigrams(prefix)
+	for trigram := range query {
+		for id := range ix.trigrams[trigram] {
+			candidates[id] = true
+		}
+	}
+
+	type ranked struct {
+		Suggestion
+		tier int
+		name string
+	}
+	var results []ranked
+	for id := range candidates {
+		name := ix.names[id]
+		score := wordSimilarity(query, trigrams(name))
+		tier := 2
+		switch {
+		case strings.HasPrefix(name, prefix):
+			tier = 0
+		case strings.Contains(" "+name, " "+prefix):
+			tier = 1
+		case score < FuzzyThreshold:
+			continue
+		}
+		results = append(results, ranked{Suggestion{ID: id, Score: score}, tier, name})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.tier != b.tier {
+			return a.tier < b.tier
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.ID < b.ID
+	})
+
+	suggestions := make([]Suggestion, len(results))
+	for i := range results {
+		suggestions[i] = results[i].Suggestion
+	}
+	return suggestions
+}
+
+// trigrams returns the trigrams of the words of a normalized text, each word padded with
+// two spaces in front and one behind like pg_trgm does
+func trigrams(text string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range strings.Fields(text) {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			result[string(runes[i:i+3])] = true
+		}
+	}
+	return result
+}
+
+// wordSimilarity is the share of the query trigrams found in the text, which approximates
+// pg_trgm word_similarity: how well the query matches some part of the text
+func wordSimilarity(query, text map[string]bool) float64 {
+	if len(query) == 0 {
+		return 0
+	}
+	shared := 0
+	for trigram := range query {
+		if text[trigram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(query))
+}
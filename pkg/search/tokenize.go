@@ -0,0 +1,121 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token is a normalized search term together with the bytes of the text it was read from
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// stopWords are left out of the index and of queries, like the English configuration of
+// Postgres full-text search does
+var stopWords = map[string]bool{
+	"a": true, "about": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "so": true, "than": true, "that": true, "the": true, "their": true, "then": true,
+	"there": true, "these": true, "they": true, "this": true, "to": true, "was": true,
+	"were": true, "will": true, "with": true,
+}
+
+// Tokenize splits text into words of letters and digits, lowercases and stems them and
+// drops stop words. Each token keeps its byte offsets in text for highlighting.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			if term := Normalize(text[start:i]); term != "" {
+				tokens = append(tokens, Token{Term: term, Start: start, End: i})
+			}
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// Normalize reduces one word to its search term, or returns "" for a stop word
+func Normalize(word string) string {
+	word = strings.ToLower(word)
+	if stopWords[word] {
+		return ""
+	}
+	return stem(word)
+}
+
+// stem strips common English inflections so that "batteries" and "battery", or "running"
+// and "run", share a term. It is a small subset of the Porter stemmer: both sides of a
+// match go through it, so it only has to be consistent, not linguistically complete.
+func stem(word string) string {
+	if utf8.RuneCountInString(word) <= 3 || !isASCIILetters(word) {
+		return word
+	}
+
+	// Plurals
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		word = word[:len(word)-1]
+	}
+
+	// Past tense and gerunds, when what remains still has a vowel
+	for _, suffix := range []string{"ing", "ed"} {
+		base := strings.TrimSuffix(word, suffix)
+		if base == word || len(base) < 3 || !hasVowel(base) {
+			continue
+		}
+		word = base
+		if n := len(word); word[n-1] == word[n-2] && !strings.ContainsRune("lsz", rune(word[n-1])) && !isVowel(word[n-1]) {
+			word = word[:n-1]
+		}
+		break
+	}
+
+	// A silent final e, so that "cable" meets "cabled" and "cabling"
+	if n := len(word); n > 4 && word[n-1] == 'e' {
+		word = word[:n-1]
+	}
+
+	// A final y after a consonant reads like the i of its inflections
+	if n := len(word); n > 2 && word[n-1] == 'y' && !isVowel(word[n-2]) {
+		word = word[:n-1] + "i"
+	}
+	return word
+}
+
+func isASCIILetters(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func hasVowel(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if isVowel(word[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
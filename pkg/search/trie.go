@@ -0,0 +1,75 @@
+package search
+
+// trie maps words to the documents containing them and finds the words starting with a prefix
+type trie struct {
+	children map[rune]*trie
+	ids      map[string]bool
+}
+
+func newTrie() *trie {
+	return &trie{children: make(map[rune]*trie)}
+}
+
+// insert records that the document contains the word
+func (t *trie) insert(word, id string) {
+	node := t
+	for _, r := range word {
+		child, ok := node.children[r]
+		if !ok {
+			child = newTrie()
+			node.children[r] = child
+		}
+		node = child
+	}
+	if node.ids == nil {
+		node.ids = make(map[string]bool)
+	}
+	node.ids[id] = true
+}
+
+// remove forgets that the document contains the word, pruning branches left empty.
+// It reports whether the node itself is now empty.
+func (t *trie) remove(word []rune, id string) bool {
+	if len(word) == 0 {
+		delete(t.ids, id)
+	} else if child, ok := t.children[word[0]]; ok && child.remove(word[1:], id) {
+		delete(t.children, word[0])
+	}
+	return len(t.ids) == 0 && len(t.children) == 0
+}
+
+// find returns the node reached by the prefix, or nil
+func (t *trie) find(prefix string) *trie {
+	node := t
+	for _, r := range prefix {
+		node = node.children[r]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// exact returns the documents containing the word
+func (t *trie) exact(word string) map[string]bool {
+	if node := t.find(word); node != nil {
+		return node.ids
+	}
+	return nil
+}
+
+// withPrefix adds the documents containing a word that starts with the prefix to result
+func (t *trie) withPrefix(prefix string, result map[string]bool) {
+	if node := t.find(prefix); node != nil {
+		node.collect(result)
+	}
+}
+
+func (t *trie) collect(result map[string]bool) {
+	for id := range t.ids {
+		result[id] = true
+	}
+	for _, child := range t.children {
+		child.collect(result)
+	}
+}
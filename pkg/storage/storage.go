@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrBlobNotFound is returned when no blob is stored under the given key
+	ErrBlobNotFound = errors.New("blob not found")
+
+	// ErrInvalidKey is returned for keys that are empty or would escape the store
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore keeps binary objects, such as product images, under slash-separated keys
+type BlobStore interface {
+	// Put stores the content read from r under key, replacing any previous blob
+	Put(ctx context.Context, key string, r io.Reader) error
+
+	// Get opens the blob stored under key; callers must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the blob stored under key; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}